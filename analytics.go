@@ -0,0 +1,455 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// StatusAnomalyReport describes the rejection rate observed on the ledger
+type StatusAnomalyReport struct {
+	TotalRecords    int     `json:"totalRecords"`
+	RejectedRecords int     `json:"rejectedRecords"`
+	RejectedRatio   float64 `json:"rejectedRatio"`
+	Anomaly         bool    `json:"anomaly"`
+}
+
+// PartyActivity reports how many history modifications a party made
+type PartyActivity struct {
+	Party         string `json:"party"`
+	Modifications int    `json:"modifications"`
+}
+
+// TimestampInconsistency reports a record whose Timestamp field disagrees with its ledger history
+type TimestampInconsistency struct {
+	ID              string `json:"id"`
+	RecordTimestamp string `json:"recordTimestamp"` // Value stored in the record's Timestamp field
+	LedgerTimestamp string `json:"ledgerTimestamp"` // Timestamp of the transaction that last wrote the record
+}
+
+// TransferCount reports how many times a record changed owners
+type TransferCount struct {
+	ID        string `json:"id"`
+	Transfers int    `json:"transfers"`
+}
+
+// VerificationTimeReport summarizes how long records took to reach VERIFIED
+type VerificationTimeReport struct {
+	VerifiedRecords int     `json:"verifiedRecords"`
+	AverageSeconds  float64 `json:"averageSeconds"`
+}
+
+// LedgerStats summarizes the records in world state for monitoring dashboards
+type LedgerStats struct {
+	TotalRecords   int            `json:"totalRecords"`
+	DeletedRecords int            `json:"deletedRecords"` // Soft-deleted records, left out of the other counts
+	ByStatus       map[string]int `json:"byStatus"`
+}
+
+// compositeKeyNamespace starts every composite key; config, index, tombstone and probe entries all live there
+const compositeKeyNamespace = "\x00"
+
+// timestampTolerance is how far a record's Timestamp may drift from its last transaction before it is flagged
+const timestampTolerance = 5 * time.Minute
+
+// GetRecordsWithMostMetadata ranks records by the number of metadata keys they carry and returns the top N.
+// Records without any metadata are not ranked. Ties are broken by record ID to keep the result deterministic.
+func (s *HistoryContract) GetRecordsWithMostMetadata(ctx contractapi.TransactionContextInterface, topN int) ([]*VerificationRecord, error) {
+	if topN <= 0 {
+		return nil, fmt.Errorf("topN must be greater than zero, got %d", topN)
+	}
+
+	records, err := s.getAllRecords(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var ranked []*VerificationRecord
+	for _, record := range records {
+		if len(record.Metadata) > 0 {
+			ranked = append(ranked, record)
+		}
+	}
+
+	sort.SliceStable(ranked, func(i, j int) bool {
+		if len(ranked[i].Metadata) != len(ranked[j].Metadata) {
+			return len(ranked[i].Metadata) > len(ranked[j].Metadata)
+		}
+		return ranked[i].ID < ranked[j].ID
+	})
+
+	if len(ranked) > topN {
+		ranked = ranked[:topN]
+	}
+
+	if err := redactConfidential(ctx, ranked...); err != nil {
+		return nil, err
+	}
+
+	return ranked, nil
+}
+
+// DetectStatusAnomalies computes the share of REJECTED records and flags an anomaly when it
+// exceeds maxRejectedRatio. The counts used for the computation are returned alongside the flag.
+func (s *HistoryContract) DetectStatusAnomalies(ctx contractapi.TransactionContextInterface, maxRejectedRatio float64) (*StatusAnomalyReport, error) {
+	if maxRejectedRatio < 0 || maxRejectedRatio > 1 {
+		return nil, fmt.Errorf("maxRejectedRatio must be between 0 and 1, got %v", maxRejectedRatio)
+	}
+
+	records, err := s.getAllRecords(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &StatusAnomalyReport{TotalRecords: len(records)}
+	for _, record := range records {
+		if record.Status == StatusRejected {
+			report.RejectedRecords++
+		}
+	}
+
+	// An empty ledger has nothing to flag
+	if report.TotalRecords > 0 {
+		report.RejectedRatio = float64(report.RejectedRecords) / float64(report.TotalRecords)
+		report.Anomaly = report.RejectedRatio > maxRejectedRatio
+	}
+
+	return report, nil
+}
+
+// GetLedgerStats counts the records in world state, in total and per status, in a single pass.
+// Reserved keys in the composite key namespace are not records and are left out of the tally. Soft-deleted
+// records are only counted in DeletedRecords, so ByStatus agrees with CountRecordsByStatus and the listings.
+func (s *HistoryContract) GetLedgerStats(ctx contractapi.TransactionContextInterface) (*LedgerStats, error) {
+	resultsIterator, err := ctx.GetStub().GetStateByRange("", "")
+	if err != nil {
+		return nil, err
+	}
+	defer resultsIterator.Close()
+
+	stats := &LedgerStats{ByStatus: map[string]int{}}
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+		if strings.HasPrefix(queryResponse.Key, compositeKeyNamespace) {
+			continue
+		}
+
+		var record VerificationRecord
+		if err := json.Unmarshal(queryResponse.Value, &record); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal record %s: %v", queryResponse.Key, err)
+		}
+		if record.Deleted {
+			stats.DeletedRecords++
+			continue
+		}
+		stats.TotalRecords++
+		stats.ByStatus[record.Status]++
+	}
+
+	return stats, nil
+}
+
+// GetHandoffMatrix counts how often custody passed from one party to another across the candidate
+// records (a JSON array of IDs). Keys have the form "A->B"; only consecutive party changes in history count.
+func (s *HistoryContract) GetHandoffMatrix(ctx contractapi.TransactionContextInterface, candidateIDsJSON string) (map[string]int, error) {
+	ids, err := parseCandidateIDs(candidateIDsJSON)
+	if err != nil {
+		return nil, err
+	}
+
+	handoffs := make(map[string]int)
+	for _, id := range ids {
+		history, err := s.getChronologicalHistory(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+
+		forEachHandoff(history, func(from string, to string) {
+			handoffs[from+"->"+to]++
+		})
+	}
+
+	return handoffs, nil
+}
+
+// GetMostTransferredRecords counts the party changes in each candidate record's history (candidates are
+// a JSON array of IDs) and returns the topN records with the most transfers. Records never transferred are left out.
+func (s *HistoryContract) GetMostTransferredRecords(ctx contractapi.TransactionContextInterface, topN int, candidateIDsJSON string) ([]TransferCount, error) {
+	if topN <= 0 {
+		return nil, fmt.Errorf("topN must be greater than zero, got %d", topN)
+	}
+
+	ids, err := parseCandidateIDs(candidateIDsJSON)
+	if err != nil {
+		return nil, err
+	}
+
+	ranked := []TransferCount{}
+	for _, id := range ids {
+		history, err := s.getChronologicalHistory(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+
+		transfers := 0
+		forEachHandoff(history, func(string, string) {
+			transfers++
+		})
+		if transfers > 0 {
+			ranked = append(ranked, TransferCount{ID: id, Transfers: transfers})
+		}
+	}
+
+	sort.SliceStable(ranked, func(i, j int) bool {
+		if ranked[i].Transfers != ranked[j].Transfers {
+			return ranked[i].Transfers > ranked[j].Transfers
+		}
+		return ranked[i].ID < ranked[j].ID
+	})
+
+	if len(ranked) > topN {
+		ranked = ranked[:topN]
+	}
+
+	return ranked, nil
+}
+
+// forEachHandoff calls fn for every consecutive party change in a chronological history.
+// A delete ends the custody chain; a re-created record starts a new one.
+func forEachHandoff(history []HistoryQueryResult, fn func(from string, to string)) {
+	previousParty := ""
+	for _, entry := range history {
+		if entry.IsDelete || entry.Record == nil {
+			previousParty = ""
+			continue
+		}
+
+		party := entry.Record.Party
+		if previousParty != "" && party != previousParty {
+			fn(previousParty, party)
+		}
+		previousParty = party
+	}
+}
+
+// GetMostActiveParty tallies history modifications per acting party across the candidate records
+// (a JSON array of IDs) and returns the busiest one. Ties go to the alphabetically first party.
+func (s *HistoryContract) GetMostActiveParty(ctx contractapi.TransactionContextInterface, candidateIDsJSON string) (*PartyActivity, error) {
+	ids, err := parseCandidateIDs(candidateIDsJSON)
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int)
+	for _, id := range ids {
+		history, err := s.GetRecordHistory(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range history {
+			if entry.IsDelete || entry.Record == nil || entry.Record.Party == "" {
+				continue
+			}
+			counts[entry.Record.Party]++
+		}
+	}
+
+	busiest := &PartyActivity{}
+	for party, count := range counts {
+		if count > busiest.Modifications || (count == busiest.Modifications && party < busiest.Party) {
+			busiest = &PartyActivity{Party: party, Modifications: count}
+		}
+	}
+
+	return busiest, nil
+}
+
+// FindMetadataConflicts finds records sharing the same value for a metadata key that is meant to be
+// unique (e.g. a serial number). The result maps each colliding value to the sorted IDs that share it.
+func (s *HistoryContract) FindMetadataConflicts(ctx contractapi.TransactionContextInterface, key string) (map[string][]string, error) {
+	if key == "" {
+		return nil, fmt.Errorf("metadata key must not be empty")
+	}
+
+	records, err := s.getAllRecords(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	idsByValue := make(map[string][]string)
+	for _, record := range records {
+		if value, ok := record.Metadata[key]; ok {
+			idsByValue[value] = append(idsByValue[value], record.ID)
+		}
+	}
+
+	conflicts := make(map[string][]string)
+	for value, ids := range idsByValue {
+		if len(ids) > 1 {
+			sort.Strings(ids)
+			conflicts[value] = ids
+		}
+	}
+
+	return conflicts, nil
+}
+
+// FindTimestampInconsistencies compares each candidate record's Timestamp field (candidates are a JSON array
+// of IDs) with the timestamp of the transaction that last wrote it and reports records that diverge by more
+// than timestampTolerance, or whose Timestamp cannot be parsed. Such records point to tampering or import errors.
+func (s *HistoryContract) FindTimestampInconsistencies(ctx contractapi.TransactionContextInterface, candidateIDsJSON string) ([]TimestampInconsistency, error) {
+	ids, err := parseCandidateIDs(candidateIDsJSON)
+	if err != nil {
+		return nil, err
+	}
+
+	inconsistencies := []TimestampInconsistency{}
+	for _, id := range ids {
+		history, err := s.getChronologicalHistory(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if len(history) == 0 {
+			continue
+		}
+
+		latest := history[len(history)-1]
+		// Deleted records have no current Timestamp to compare
+		if latest.IsDelete || latest.Record == nil {
+			continue
+		}
+
+		recordTime, err := time.Parse(time.RFC3339, latest.Record.Timestamp)
+		drift := recordTime.Sub(latest.Timestamp)
+		if err != nil || drift > timestampTolerance || drift < -timestampTolerance {
+			inconsistencies = append(inconsistencies, TimestampInconsistency{
+				ID:              id,
+				RecordTimestamp: latest.Record.Timestamp,
+				LedgerTimestamp: latest.Timestamp.UTC().Format(time.RFC3339),
+			})
+		}
+	}
+
+	return inconsistencies, nil
+}
+
+// GetAverageTimeToVerify computes, over the candidate records (a JSON array of IDs), the average time
+// between a record's first history entry and its first transition to VERIFIED. Records never verified are skipped.
+func (s *HistoryContract) GetAverageTimeToVerify(ctx contractapi.TransactionContextInterface, candidateIDsJSON string) (*VerificationTimeReport, error) {
+	ids, err := parseCandidateIDs(candidateIDsJSON)
+	if err != nil {
+		return nil, err
+	}
+
+	var total time.Duration
+	report := &VerificationTimeReport{}
+	for _, id := range ids {
+		history, err := s.getChronologicalHistory(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+
+		var createdAt time.Time
+		for _, entry := range history {
+			if entry.IsDelete || entry.Record == nil {
+				continue
+			}
+			if createdAt.IsZero() {
+				createdAt = entry.Timestamp
+			}
+			if entry.Record.Status == StatusVerified {
+				total += entry.Timestamp.Sub(createdAt)
+				report.VerifiedRecords++
+				break
+			}
+		}
+	}
+
+	if report.VerifiedRecords > 0 {
+		report.AverageSeconds = total.Seconds() / float64(report.VerifiedRecords)
+	}
+
+	return report, nil
+}
+
+// GetRecordsGroupedByDate groups records by the UTC date (YYYY-MM-DD) they were created on.
+// Records whose timestamp cannot be parsed are left out of the grouping.
+func (s *HistoryContract) GetRecordsGroupedByDate(ctx contractapi.TransactionContextInterface) (map[string][]*VerificationRecord, error) {
+	records, err := s.getAllRecords(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := redactConfidential(ctx, records...); err != nil {
+		return nil, err
+	}
+
+	groups := make(map[string][]*VerificationRecord)
+	for _, record := range records {
+		createdAt, err := creationTime(record)
+		if err != nil {
+			continue
+		}
+		date := createdAt.UTC().Format("2006-01-02")
+		groups[date] = append(groups[date], record)
+	}
+
+	return groups, nil
+}
+
+// GetActivityByPartyAndHour counts record creations per party and UTC hour of day ("00" to "23")
+// in a single scan. Records whose timestamp cannot be parsed are skipped.
+func (s *HistoryContract) GetActivityByPartyAndHour(ctx contractapi.TransactionContextInterface) (map[string]map[string]int, error) {
+	records, err := s.getAllRecords(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	activity := make(map[string]map[string]int)
+	for _, record := range records {
+		createdAt, err := creationTime(record)
+		if err != nil {
+			continue
+		}
+
+		hours, ok := activity[record.Party]
+		if !ok {
+			hours = make(map[string]int)
+			activity[record.Party] = hours
+		}
+		hours[fmt.Sprintf("%02d", createdAt.UTC().Hour())]++
+	}
+
+	return activity, nil
+}
+
+// creationTime returns when a record was created. Records written before CreatedAt existed
+// (or imported without it) fall back to their Timestamp field.
+func creationTime(record *VerificationRecord) (time.Time, error) {
+	value := record.CreatedAt
+	if value == "" {
+		value = record.Timestamp
+	}
+	return time.Parse(time.RFC3339, value)
+}
+
+// parseCandidateIDs decodes a JSON array of record IDs used to scope history based scans
+func parseCandidateIDs(candidateIDsJSON string) ([]string, error) {
+	var ids []string
+	if err := json.Unmarshal([]byte(candidateIDsJSON), &ids); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal candidate IDs: %v", err)
+	}
+	for _, id := range ids {
+		if id == "" {
+			return nil, fmt.Errorf("candidate IDs must not be empty")
+		}
+	}
+	return ids, nil
+}
@@ -0,0 +1,282 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-protos-go/ledger/queryresult"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetRecordsWithMostMetadata(t *testing.T) {
+	t.Log("Starting TestGetRecordsWithMostMetadata: Verifying records are ranked by metadata key count")
+	ctx, stub, _ := newMockContext("Org1MSP")
+
+	records := []VerificationRecord{
+		{ID: "REC001", Metadata: map[string]string{"location": "Thika"}},
+		{ID: "REC002", Metadata: map[string]string{"location": "Nairobi", "batch": "B7", "carrier": "DHL"}},
+		{ID: "REC003", Metadata: map[string]string{"location": "Mombasa", "batch": "B8"}},
+	}
+	stub.On("GetStateByRange", "", "").Return(newStateIterator(t, records...), nil)
+
+	t.Log("Invoking GetRecordsWithMostMetadata with topN=2...")
+	contract := new(HistoryContract)
+	ranked, err := contract.GetRecordsWithMostMetadata(ctx, 2)
+
+	assert.NoError(t, err)
+	assert.Len(t, ranked, 2)
+	assert.Equal(t, "REC002", ranked[0].ID)
+	assert.Equal(t, "REC003", ranked[1].ID)
+	t.Log("Records were ranked by descending metadata count")
+}
+
+func TestDetectStatusAnomaliesFlagsHighRejectionRate(t *testing.T) {
+	t.Log("Starting TestDetectStatusAnomaliesFlagsHighRejectionRate: Verifying a rejection spike is flagged")
+	ctx, stub, _ := newMockContext("Org1MSP")
+
+	records := []VerificationRecord{
+		{ID: "REC001", Status: "REJECTED"},
+		{ID: "REC002", Status: "REJECTED"},
+		{ID: "REC003", Status: "REJECTED"},
+		{ID: "REC004", Status: "VERIFIED"},
+	}
+	stub.On("GetStateByRange", "", "").Return(newStateIterator(t, records...), nil)
+
+	t.Log("Invoking DetectStatusAnomalies with a 50% threshold...")
+	contract := new(HistoryContract)
+	report, err := contract.DetectStatusAnomalies(ctx, 0.5)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 4, report.TotalRecords)
+	assert.Equal(t, 3, report.RejectedRecords)
+	assert.InDelta(t, 0.75, report.RejectedRatio, 0.0001)
+	assert.True(t, report.Anomaly)
+	t.Log("Rejected ratio of 0.75 exceeded the threshold and was flagged")
+}
+
+func TestGetLedgerStats(t *testing.T) {
+	t.Log("Starting TestGetLedgerStats: Verifying the total and per status counts over a mixed ledger")
+	ctx, stub, _ := newMockContext("Org1MSP")
+
+	iterator := newKVIterator(
+		&queryresult.KV{Key: "REC001", Value: mustMarshal(t, VerificationRecord{ID: "REC001", Status: "CREATED"})},
+		&queryresult.KV{Key: "REC002", Value: mustMarshal(t, VerificationRecord{ID: "REC002", Status: "PENDING"})},
+		&queryresult.KV{Key: "REC003", Value: mustMarshal(t, VerificationRecord{ID: "REC003", Status: "PENDING", Deleted: true})},
+		&queryresult.KV{Key: "REC004", Value: mustMarshal(t, VerificationRecord{ID: "REC004", Status: "VERIFIED"})},
+		&queryresult.KV{Key: compositeKey(configObjectType, configDefaultPageSize), Value: []byte("50")},
+	)
+	stub.On("GetStateByRange", "", "").Return(iterator, nil)
+
+	contract := new(HistoryContract)
+	stats, err := contract.GetLedgerStats(ctx)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 3, stats.TotalRecords)
+	assert.Equal(t, 1, stats.DeletedRecords)
+	assert.Equal(t, map[string]int{"CREATED": 1, "PENDING": 1, "VERIFIED": 1}, stats.ByStatus)
+	t.Log("The config entry was skipped, the soft-deleted record counted apart and the rest counted under their status")
+}
+
+func TestGetHandoffMatrix(t *testing.T) {
+	t.Log("Starting TestGetHandoffMatrix: Verifying custody handoffs are counted between parties")
+	ctx, stub, _ := newMockContext("Org1MSP")
+
+	base := time.Date(2024, 3, 1, 8, 0, 0, 0, time.UTC)
+	stub.On("GetHistoryForKey", "REC001").Return(newHistoryIterator(
+		historyEntry(t, "tx1", base, &VerificationRecord{ID: "REC001", Party: "Org1MSP", Status: "CREATED"}),
+		historyEntry(t, "tx2", base.Add(time.Hour), &VerificationRecord{ID: "REC001", Party: "Org1MSP", Status: "PENDING"}),
+		historyEntry(t, "tx3", base.Add(2*time.Hour), &VerificationRecord{ID: "REC001", Party: "Org2MSP", Status: "VERIFIED"}),
+	), nil)
+	stub.On("GetHistoryForKey", "REC002").Return(newHistoryIterator(
+		historyEntry(t, "tx4", base, &VerificationRecord{ID: "REC002", Party: "Org1MSP", Status: "CREATED"}),
+		historyEntry(t, "tx5", base.Add(time.Hour), &VerificationRecord{ID: "REC002", Party: "Org2MSP", Status: "PENDING"}),
+		historyEntry(t, "tx6", base.Add(2*time.Hour), &VerificationRecord{ID: "REC002", Party: "Org3MSP", Status: "PENDING"}),
+	), nil)
+
+	t.Log("Invoking GetHandoffMatrix over two records...")
+	contract := new(HistoryContract)
+	matrix, err := contract.GetHandoffMatrix(ctx, `["REC001","REC002"]`)
+
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]int{"Org1MSP->Org2MSP": 2, "Org2MSP->Org3MSP": 1}, matrix)
+	t.Log("Two Org1MSP->Org2MSP handoffs and one Org2MSP->Org3MSP handoff were counted")
+}
+
+func TestGetRecordsGroupedByDate(t *testing.T) {
+	t.Log("Starting TestGetRecordsGroupedByDate: Verifying records are grouped by creation date")
+	ctx, stub, _ := newMockContext("Org1MSP")
+
+	records := []VerificationRecord{
+		{ID: "REC001", CreatedAt: "2024-03-01T08:00:00Z", Timestamp: "2024-03-05T10:00:00Z"},
+		{ID: "REC002", Timestamp: "2024-03-02T23:30:00+03:00"},
+		{ID: "REC003", Timestamp: "not-a-timestamp"},
+	}
+	stub.On("GetStateByRange", "", "").Return(newStateIterator(t, records...), nil)
+
+	contract := new(HistoryContract)
+	groups, err := contract.GetRecordsGroupedByDate(ctx)
+
+	assert.NoError(t, err)
+	assert.Len(t, groups, 2)
+	assert.Equal(t, "REC001", groups["2024-03-01"][0].ID)
+	// 23:30 at UTC+3 is still the 2nd in UTC
+	assert.Equal(t, "REC002", groups["2024-03-02"][0].ID)
+	t.Log("Records were grouped by their UTC creation date")
+}
+
+func TestGetMostActiveParty(t *testing.T) {
+	t.Log("Starting TestGetMostActiveParty: Verifying the party with the most modifications is returned")
+	ctx, stub, _ := newMockContext("Org1MSP")
+
+	base := time.Date(2024, 3, 1, 8, 0, 0, 0, time.UTC)
+	stub.On("GetHistoryForKey", "REC001").Return(newHistoryIterator(
+		historyEntry(t, "tx1", base, &VerificationRecord{ID: "REC001", Party: "Org1MSP"}),
+		historyEntry(t, "tx2", base.Add(time.Hour), &VerificationRecord{ID: "REC001", Party: "Org2MSP"}),
+	), nil)
+	stub.On("GetHistoryForKey", "REC002").Return(newHistoryIterator(
+		historyEntry(t, "tx3", base, &VerificationRecord{ID: "REC002", Party: "Org2MSP"}),
+		historyEntry(t, "tx4", base.Add(time.Hour), &VerificationRecord{ID: "REC002", Party: "Org2MSP"}),
+		historyEntry(t, "tx5", base.Add(2*time.Hour), nil),
+	), nil)
+
+	contract := new(HistoryContract)
+	activity, err := contract.GetMostActiveParty(ctx, `["REC001","REC002"]`)
+
+	assert.NoError(t, err)
+	assert.Equal(t, &PartyActivity{Party: "Org2MSP", Modifications: 3}, activity)
+	t.Log("Org2MSP was reported with three modifications")
+}
+
+func TestFindMetadataConflicts(t *testing.T) {
+	t.Log("Starting TestFindMetadataConflicts: Verifying records sharing a serial number are flagged")
+	ctx, stub, _ := newMockContext("Org1MSP")
+
+	records := []VerificationRecord{
+		{ID: "REC003", Metadata: map[string]string{"serial": "SN-100"}},
+		{ID: "REC001", Metadata: map[string]string{"serial": "SN-100"}},
+		{ID: "REC002", Metadata: map[string]string{"serial": "SN-200"}},
+		{ID: "REC004"},
+	}
+	stub.On("GetStateByRange", "", "").Return(newStateIterator(t, records...), nil)
+
+	contract := new(HistoryContract)
+	conflicts, err := contract.FindMetadataConflicts(ctx, "serial")
+
+	assert.NoError(t, err)
+	assert.Equal(t, map[string][]string{"SN-100": {"REC001", "REC003"}}, conflicts)
+	t.Log("REC001 and REC003 were reported as sharing SN-100")
+}
+
+func TestFindMetadataConflictsEmpty(t *testing.T) {
+	ctx, stub, _ := newMockContext("Org1MSP")
+	stub.On("GetStateByRange", "", "").Return(newStateIterator(t, VerificationRecord{ID: "REC001"}), nil)
+
+	contract := new(HistoryContract)
+	conflicts, err := contract.FindMetadataConflicts(ctx, "serial")
+
+	assert.NoError(t, err)
+	assert.NotNil(t, conflicts)
+	assert.Empty(t, conflicts)
+}
+
+func TestFindTimestampInconsistencies(t *testing.T) {
+	t.Log("Starting TestFindTimestampInconsistencies: Verifying a stale Timestamp field is flagged")
+	ctx, stub, _ := newMockContext("Org1MSP")
+
+	lastWrite := time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+	stub.On("GetHistoryForKey", "REC001").Return(newHistoryIterator(
+		historyEntry(t, "tx1", lastWrite.Add(-time.Hour), &VerificationRecord{ID: "REC001", Timestamp: "2024-03-10T11:00:00Z"}),
+		// The field claims an earlier time than the transaction that wrote it
+		historyEntry(t, "tx2", lastWrite, &VerificationRecord{ID: "REC001", Timestamp: "2024-01-01T00:00:00Z"}),
+	), nil)
+	stub.On("GetHistoryForKey", "REC002").Return(newHistoryIterator(
+		historyEntry(t, "tx3", lastWrite, &VerificationRecord{ID: "REC002", Timestamp: "2024-03-10T12:00:30Z"}),
+	), nil)
+
+	contract := new(HistoryContract)
+	inconsistencies, err := contract.FindTimestampInconsistencies(ctx, `["REC001","REC002"]`)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []TimestampInconsistency{
+		{ID: "REC001", RecordTimestamp: "2024-01-01T00:00:00Z", LedgerTimestamp: "2024-03-10T12:00:00Z"},
+	}, inconsistencies)
+	t.Log("Only REC001 was flagged; REC002 is within tolerance")
+}
+
+func TestGetAverageTimeToVerify(t *testing.T) {
+	t.Log("Starting TestGetAverageTimeToVerify: Verifying the average creation-to-verification time")
+	ctx, stub, _ := newMockContext("Org1MSP")
+
+	base := time.Date(2024, 3, 1, 8, 0, 0, 0, time.UTC)
+	stub.On("GetHistoryForKey", "REC001").Return(newHistoryIterator(
+		historyEntry(t, "tx1", base, &VerificationRecord{ID: "REC001", Status: "CREATED"}),
+		historyEntry(t, "tx2", base.Add(time.Hour), &VerificationRecord{ID: "REC001", Status: "PENDING"}),
+		historyEntry(t, "tx3", base.Add(2*time.Hour), &VerificationRecord{ID: "REC001", Status: "VERIFIED"}),
+	), nil)
+	stub.On("GetHistoryForKey", "REC002").Return(newHistoryIterator(
+		historyEntry(t, "tx4", base, &VerificationRecord{ID: "REC002", Status: "PENDING"}),
+		historyEntry(t, "tx5", base.Add(4*time.Hour), &VerificationRecord{ID: "REC002", Status: "VERIFIED"}),
+	), nil)
+	stub.On("GetHistoryForKey", "REC003").Return(newHistoryIterator(
+		historyEntry(t, "tx6", base, &VerificationRecord{ID: "REC003", Status: "PENDING"}),
+	), nil)
+
+	contract := new(HistoryContract)
+	report, err := contract.GetAverageTimeToVerify(ctx, `["REC001","REC002","REC003"]`)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, report.VerifiedRecords)
+	assert.InDelta(t, (3 * time.Hour).Seconds(), report.AverageSeconds, 0.001)
+	t.Log("The unverified record was skipped and the average is three hours")
+}
+
+func TestGetActivityByPartyAndHour(t *testing.T) {
+	t.Log("Starting TestGetActivityByPartyAndHour: Verifying creations are counted per party and hour")
+	ctx, stub, _ := newMockContext("Org1MSP")
+
+	records := []VerificationRecord{
+		{ID: "REC001", Party: "Org1MSP", CreatedAt: "2024-03-01T08:15:00Z"},
+		{ID: "REC002", Party: "Org1MSP", CreatedAt: "2024-03-02T08:45:00Z"},
+		{ID: "REC003", Party: "Org2MSP", CreatedAt: "2024-03-01T17:05:00Z"},
+		{ID: "REC004", Party: "Org2MSP", CreatedAt: "yesterday"},
+	}
+	stub.On("GetStateByRange", "", "").Return(newStateIterator(t, records...), nil)
+
+	contract := new(HistoryContract)
+	activity, err := contract.GetActivityByPartyAndHour(ctx)
+
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]map[string]int{
+		"Org1MSP": {"08": 2},
+		"Org2MSP": {"17": 1},
+	}, activity)
+	t.Log("Counts were grouped by party and hour, skipping the unparseable timestamp")
+}
+
+func TestGetMostTransferredRecords(t *testing.T) {
+	t.Log("Starting TestGetMostTransferredRecords: Verifying records are ranked by transfer count")
+	ctx, stub, _ := newMockContext("Org1MSP")
+
+	base := time.Date(2024, 3, 1, 8, 0, 0, 0, time.UTC)
+	stub.On("GetHistoryForKey", "REC001").Return(newHistoryIterator(
+		historyEntry(t, "tx1", base, &VerificationRecord{ID: "REC001", Party: "Org1MSP"}),
+		historyEntry(t, "tx2", base.Add(time.Hour), &VerificationRecord{ID: "REC001", Party: "Org2MSP"}),
+	), nil)
+	stub.On("GetHistoryForKey", "REC002").Return(newHistoryIterator(
+		historyEntry(t, "tx3", base, &VerificationRecord{ID: "REC002", Party: "Org1MSP"}),
+		historyEntry(t, "tx4", base.Add(time.Hour), &VerificationRecord{ID: "REC002", Party: "Org1MSP"}),
+		historyEntry(t, "tx5", base.Add(2*time.Hour), &VerificationRecord{ID: "REC002", Party: "Org2MSP"}),
+		historyEntry(t, "tx6", base.Add(3*time.Hour), &VerificationRecord{ID: "REC002", Party: "Org3MSP"}),
+		historyEntry(t, "tx7", base.Add(4*time.Hour), &VerificationRecord{ID: "REC002", Party: "Org1MSP"}),
+	), nil)
+	stub.On("GetHistoryForKey", "REC003").Return(newHistoryIterator(
+		historyEntry(t, "tx8", base, &VerificationRecord{ID: "REC003", Party: "Org3MSP"}),
+	), nil)
+
+	contract := new(HistoryContract)
+	ranked, err := contract.GetMostTransferredRecords(ctx, 5, `["REC001","REC002","REC003"]`)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []TransferCount{{ID: "REC002", Transfers: 3}, {ID: "REC001", Transfers: 1}}, ranked)
+	t.Log("REC002 ranked first with three transfers; the untransferred record was excluded")
+}
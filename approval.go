@@ -0,0 +1,343 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// SignoffGap reports a VERIFIED record that is missing sign-offs. MissingApprovers names the required
+// approvers that have not signed off; records without required approvers only report MissingApprovals.
+type SignoffGap struct {
+	ID               string   `json:"id"`
+	MissingApprovers []string `json:"missingApprovers"`
+	MissingApprovals int      `json:"missingApprovals"` // Number of sign-offs short of what verification needs
+}
+
+// RejectionEvent is the payload of the RecordRejected chaincode event
+type RejectionEvent struct {
+	ID        string `json:"id"`
+	OldStatus string `json:"oldStatus"`
+	Reason    string `json:"reason"`
+	Party     string `json:"party"`
+	Timestamp string `json:"timestamp"`
+}
+
+// SetRequiredApprovers defines which organizations must sign off on a record.
+// Only the owning party or the admin MSP may set the list.
+func (s *HistoryContract) SetRequiredApprovers(ctx contractapi.TransactionContextInterface, id string, approvers []string) error {
+	record, err := s.readRecord(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if _, err := authorizeOwner(ctx, record, "set approvers on"); err != nil {
+		return err
+	}
+
+	seen := make(map[string]bool)
+	var required []string
+	for _, approver := range approvers {
+		if approver == "" {
+			return fmt.Errorf("approver MSP ID must not be empty")
+		}
+		if seen[approver] {
+			continue
+		}
+		seen[approver] = true
+		required = append(required, approver)
+	}
+
+	timestamp, err := txTimestampString(ctx)
+	if err != nil {
+		return err
+	}
+
+	record.RequiredApprovers = required
+	record.Timestamp = timestamp
+	return s.putRecord(ctx, record)
+}
+
+// ApproveRecord adds the caller's sign-off to a record. Once enough distinct organizations have approved,
+// the record moves to VERIFIED: all of its required approvers when it has any, otherwise the configured
+// approval threshold. Each organization may approve a record only once.
+func (s *HistoryContract) ApproveRecord(ctx contractapi.TransactionContextInterface, id string) error {
+	record, err := s.readRecord(ctx, id)
+	if err != nil {
+		return err
+	}
+	if record.Status == StatusVerified {
+		return fmt.Errorf("record %s is already %s", id, StatusVerified)
+	}
+
+	clientIdentity, err := ctx.GetClientIdentity().GetMSPID()
+	if err != nil {
+		return fmt.Errorf("failed to get client identity: %v", err)
+	}
+	if slices.Contains(record.Approvals, clientIdentity) {
+		return fmt.Errorf("organization %s has already approved record %s", clientIdentity, id)
+	}
+	if len(record.RequiredApprovers) > 0 && !slices.Contains(record.RequiredApprovers, clientIdentity) {
+		return fmt.Errorf("organization %s is not a required approver of record %s: %w", clientIdentity, id, ErrUnauthorized)
+	}
+
+	record.Approvals = append(record.Approvals, clientIdentity)
+
+	approved := false
+	if len(record.RequiredApprovers) > 0 {
+		approved = len(missingApprovers(record)) == 0
+	} else {
+		threshold, err := getApprovalThreshold(ctx)
+		if err != nil {
+			return err
+		}
+		approved = len(record.Approvals) >= threshold
+	}
+
+	oldStatus := record.Status
+	if approved {
+		allowed, err := isValidTransition(ctx, record.Status, StatusVerified)
+		if err != nil {
+			return err
+		}
+		if !allowed {
+			return fmt.Errorf("invalid status transition from %s to %s: %w", record.Status, StatusVerified, ErrInvalidStatus)
+		}
+		record.Status = StatusVerified
+	}
+
+	timestamp, err := txTimestampString(ctx)
+	if err != nil {
+		return err
+	}
+	record.Timestamp = timestamp
+
+	if err := s.putRecord(ctx, record); err != nil {
+		return err
+	}
+	if approved {
+		return emitStatusChange(ctx, oldStatus, record)
+	}
+	return nil
+}
+
+// RejectRecord moves a record to REJECTED and stores why, so downstream parties know what to fix.
+// A reason is required. Only the owning party or the admin MSP may reject, and the move must be allowed
+// by the workflow. A RecordRejected event carrying the reason is emitted.
+func (s *HistoryContract) RejectRecord(ctx contractapi.TransactionContextInterface, id string, reason string) error {
+	if reason == "" {
+		return fmt.Errorf("a reason is required to reject record %s", id)
+	}
+
+	record, err := s.readRecord(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	clientIdentity, err := authorizeOwner(ctx, record, "reject")
+	if err != nil {
+		return err
+	}
+
+	allowed, err := isValidTransition(ctx, record.Status, StatusRejected)
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		return fmt.Errorf("invalid status transition from %s to %s: %w", record.Status, StatusRejected, ErrInvalidStatus)
+	}
+
+	timestamp, err := txTimestampString(ctx)
+	if err != nil {
+		return err
+	}
+
+	oldStatus := record.Status
+	record.Status = StatusRejected
+	record.RejectionReason = reason
+	record.Timestamp = timestamp
+	if err := s.putRecord(ctx, record); err != nil {
+		return err
+	}
+
+	eventJSON, err := json.Marshal(RejectionEvent{ID: id, OldStatus: oldStatus, Reason: reason, Party: clientIdentity, Timestamp: timestamp})
+	if err != nil {
+		return err
+	}
+	if err := ctx.GetStub().SetEvent("RecordRejected", eventJSON); err != nil {
+		return fmt.Errorf("failed to emit RecordRejected event for %s: %v", id, err)
+	}
+	return nil
+}
+
+// ProposeStatusChange records a pending move of a record to newStatus without applying it. The change only
+// takes effect once another organization calls CommitStatusChange. Only the owning party or the admin MSP
+// may propose, the move must be allowed by the workflow, and a record holds at most one proposal at a time.
+// A proposal is discarded when any other write changes the record's status first.
+func (s *HistoryContract) ProposeStatusChange(ctx contractapi.TransactionContextInterface, id string, newStatus string) error {
+	record, err := s.readRecord(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := ensureMutable(record); err != nil {
+		return err
+	}
+	if record.ProposedStatus != "" {
+		return fmt.Errorf("record %s already has a pending proposal to move to %s", id, record.ProposedStatus)
+	}
+
+	clientIdentity, err := authorizeOwner(ctx, record, "propose a status change for")
+	if err != nil {
+		return err
+	}
+
+	allowed, err := isValidTransition(ctx, record.Status, newStatus)
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		return fmt.Errorf("invalid status transition from %s to %s: %w", record.Status, newStatus, ErrInvalidStatus)
+	}
+
+	timestamp, err := txTimestampString(ctx)
+	if err != nil {
+		return err
+	}
+	record.ProposedStatus = newStatus
+	record.ProposedBy = clientIdentity
+	record.Timestamp = timestamp
+	return s.putRecord(ctx, record)
+}
+
+// CommitStatusChange applies the status proposed by ProposeStatusChange and clears the proposal.
+// Any organization other than the proposer may commit, so no single party can push a change through
+// on its own. The move is checked against the workflow again in case it changed since the proposal.
+func (s *HistoryContract) CommitStatusChange(ctx contractapi.TransactionContextInterface, id string) error {
+	record, err := s.readRecord(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := ensureMutable(record); err != nil {
+		return err
+	}
+	if record.ProposedStatus == "" {
+		return fmt.Errorf("record %s has no pending status change to commit", id)
+	}
+
+	clientIdentity, err := ctx.GetClientIdentity().GetMSPID()
+	if err != nil {
+		return fmt.Errorf("failed to get client identity: %v", err)
+	}
+	if clientIdentity == record.ProposedBy {
+		return fmt.Errorf("organization %s proposed the change to record %s and cannot also commit it: %w", clientIdentity, id, ErrUnauthorized)
+	}
+
+	allowed, err := isValidTransition(ctx, record.Status, record.ProposedStatus)
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		return fmt.Errorf("invalid status transition from %s to %s: %w", record.Status, record.ProposedStatus, ErrInvalidStatus)
+	}
+
+	timestamp, err := txTimestampString(ctx)
+	if err != nil {
+		return err
+	}
+
+	oldStatus := record.Status
+	record.Status = record.ProposedStatus
+	record.ProposedStatus = ""
+	record.ProposedBy = ""
+	record.Timestamp = timestamp
+	if err := s.putRecord(ctx, record); err != nil {
+		return err
+	}
+	return emitStatusChange(ctx, oldStatus, record)
+}
+
+// GetRecordsAwaitingMyApproval returns the records that list the caller as a required approver
+// but do not yet carry the caller's sign-off. It gives each organization a personal to-do list.
+// Records that can no longer be approved, because they are already VERIFIED or soft-deleted, are left out.
+func (s *HistoryContract) GetRecordsAwaitingMyApproval(ctx contractapi.TransactionContextInterface) ([]*VerificationRecord, error) {
+	clientIdentity, err := ctx.GetClientIdentity().GetMSPID()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get client identity: %v", err)
+	}
+
+	records, err := s.getAllRecords(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var pending []*VerificationRecord
+	for _, record := range records {
+		if record.Deleted || record.Status == StatusVerified {
+			continue
+		}
+		if slices.Contains(record.RequiredApprovers, clientIdentity) && !slices.Contains(record.Approvals, clientIdentity) {
+			pending = append(pending, record)
+		}
+	}
+
+	if err := redactConfidential(ctx, pending...); err != nil {
+		return nil, err
+	}
+
+	return pending, nil
+}
+
+// AuditVerifiedSignoffs checks the candidate records (a JSON array of IDs) and returns the VERIFIED ones
+// that lack sign-offs, e.g. records verified through an admin override or before approvals existed.
+// Records with required approvers must carry all of their sign-offs; the others at least as many as the
+// configured approval threshold, as in ApproveRecord.
+func (s *HistoryContract) AuditVerifiedSignoffs(ctx contractapi.TransactionContextInterface, candidateIDsJSON string) ([]SignoffGap, error) {
+	ids, err := parseCandidateIDs(candidateIDsJSON)
+	if err != nil {
+		return nil, err
+	}
+
+	threshold := 0
+	gaps := []SignoffGap{}
+	for _, id := range ids {
+		record, err := s.readRecord(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if record.Status != StatusVerified {
+			continue
+		}
+
+		if len(record.RequiredApprovers) > 0 {
+			if missing := missingApprovers(record); len(missing) > 0 {
+				gaps = append(gaps, SignoffGap{ID: id, MissingApprovers: missing, MissingApprovals: len(missing)})
+			}
+			continue
+		}
+
+		if threshold == 0 {
+			threshold, err = getApprovalThreshold(ctx)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if len(record.Approvals) < threshold {
+			gaps = append(gaps, SignoffGap{ID: id, MissingApprovers: []string{}, MissingApprovals: threshold - len(record.Approvals)})
+		}
+	}
+
+	return gaps, nil
+}
+
+// missingApprovers lists the required approvers of a record that have not signed off yet
+func missingApprovers(record *VerificationRecord) []string {
+	var missing []string
+	for _, approver := range record.RequiredApprovers {
+		if !slices.Contains(record.Approvals, approver) {
+			missing = append(missing, approver)
+		}
+	}
+	return missing
+}
@@ -0,0 +1,332 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestGetRecordsAwaitingMyApproval(t *testing.T) {
+	t.Log("Starting TestGetRecordsAwaitingMyApproval: Verifying the caller's approval to-do list")
+	ctx, stub, _ := newMockContext("Org2MSP")
+
+	records := []VerificationRecord{
+		{ID: "REC001", Party: "Org1MSP", Status: "PENDING", RequiredApprovers: []string{"Org2MSP", "Org3MSP"}},
+		{ID: "REC002", Party: "Org1MSP", Status: "PENDING", RequiredApprovers: []string{"Org2MSP"}, Approvals: []string{"Org2MSP"}},
+		{ID: "REC003", Party: "Org3MSP", Status: "PENDING", RequiredApprovers: []string{"Org3MSP"}},
+		// Verified through an admin override and soft-deleted records cannot be approved any more
+		{ID: "REC004", Party: "Org1MSP", Status: "VERIFIED", RequiredApprovers: []string{"Org2MSP"}},
+		{ID: "REC005", Party: "Org1MSP", Status: "PENDING", RequiredApprovers: []string{"Org2MSP"}, Deleted: true},
+	}
+	stub.On("GetStateByRange", "", "").Return(newStateIterator(t, records...), nil)
+
+	t.Log("Invoking GetRecordsAwaitingMyApproval as Org2MSP...")
+	contract := new(HistoryContract)
+	pending, err := contract.GetRecordsAwaitingMyApproval(ctx)
+
+	assert.NoError(t, err)
+	assert.Len(t, pending, 1)
+	assert.Equal(t, "REC001", pending[0].ID)
+	t.Log("Only the approvable record still missing Org2MSP's sign-off was returned")
+}
+
+func TestSetRequiredApproversRejectsNonOwner(t *testing.T) {
+	t.Log("Starting TestSetRequiredApproversRejectsNonOwner: Verifying only the owner can set approvers")
+	ctx, stub, _ := newMockContext("Org3MSP")
+
+	record := VerificationRecord{ID: "REC001", Party: "Org2MSP", Status: "PENDING"}
+	stub.On("GetState", "REC001").Return(mustMarshal(t, record), nil)
+
+	contract := new(HistoryContract)
+	err := contract.SetRequiredApprovers(ctx, "REC001", []string{"Org3MSP"})
+
+	assert.EqualError(t, err, "caller Org3MSP is not authorized to set approvers on record owned by Org2MSP: unauthorized")
+	stub.AssertNotCalled(t, "PutState", mock.Anything, mock.Anything)
+}
+
+func TestAuditVerifiedSignoffsFlagsMissingApproval(t *testing.T) {
+	t.Log("Starting TestAuditVerifiedSignoffsFlagsMissingApproval: Verifying under-approved verifications are reported")
+	ctx, stub, _ := newMockContext("Org1MSP")
+
+	complete := VerificationRecord{ID: "REC001", Status: "VERIFIED", RequiredApprovers: []string{"Org2MSP", "Org3MSP"}, Approvals: []string{"Org3MSP", "Org2MSP"}}
+	incomplete := VerificationRecord{ID: "REC002", Status: "VERIFIED", RequiredApprovers: []string{"Org2MSP", "Org3MSP"}, Approvals: []string{"Org2MSP"}}
+	pending := VerificationRecord{ID: "REC003", Status: "PENDING", RequiredApprovers: []string{"Org2MSP"}}
+	// Verified before approvals existed: no required approvers and no sign-offs at all
+	legacy := VerificationRecord{ID: "REC004", Status: "VERIFIED"}
+	threshold := VerificationRecord{ID: "REC005", Status: "VERIFIED", Approvals: []string{"Org2MSP", "Org3MSP"}}
+	stub.On("GetState", "REC001").Return(mustMarshal(t, complete), nil)
+	stub.On("GetState", "REC002").Return(mustMarshal(t, incomplete), nil)
+	stub.On("GetState", "REC003").Return(mustMarshal(t, pending), nil)
+	stub.On("GetState", "REC004").Return(mustMarshal(t, legacy), nil)
+	stub.On("GetState", "REC005").Return(mustMarshal(t, threshold), nil)
+	stub.On("GetState", compositeKey(configObjectType, configApprovalThreshold)).Return(nil, nil)
+
+	t.Log("Invoking AuditVerifiedSignoffs over five candidates...")
+	contract := new(HistoryContract)
+	gaps, err := contract.AuditVerifiedSignoffs(ctx, `["REC001","REC002","REC003","REC004","REC005"]`)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []SignoffGap{
+		{ID: "REC002", MissingApprovers: []string{"Org3MSP"}, MissingApprovals: 1},
+		{ID: "REC004", MissingApprovers: []string{}, MissingApprovals: defaultApprovalThreshold},
+	}, gaps)
+	t.Log("The record missing Org3MSP's approval and the one verified without any approvals were flagged")
+}
+
+func TestApproveRecordReachesThreshold(t *testing.T) {
+	t.Log("Starting TestApproveRecordReachesThreshold: Verifying the record is verified only on the final approval")
+	record := VerificationRecord{ID: "REC001", Party: "Org1MSP", Status: "PENDING"}
+	contract := new(HistoryContract)
+
+	approve := func(mspID string) (VerificationRecord, *MockChaincodeStub, error) {
+		ctx, stub, _ := newMockContext(mspID)
+		stub.On("GetState", "REC001").Return(mustMarshal(t, record), nil)
+		stub.On("GetState", compositeKey(configObjectType, configApprovalThreshold)).Return(nil, nil)
+		stub.On("GetState", compositeKey(configObjectType, configWorkflowGraph)).Return(nil, nil)
+		stub.On("GetTxTimestamp").Return(timestamppb.Now(), nil)
+		var written VerificationRecord
+		stub.On("PutState", "REC001", mock.Anything).Run(func(args mock.Arguments) {
+			_ = json.Unmarshal(args.Get(1).([]byte), &written)
+		}).Return(nil)
+		stub.On("PutState", mock.Anything, indexEntryValue).Return(nil)
+		stub.On("DelState", mock.Anything).Return(nil)
+		stub.On("SetEvent", "RecordUpdated", mock.Anything).Return(nil)
+		err := contract.ApproveRecord(ctx, "REC001")
+		return written, stub, err
+	}
+
+	t.Log("First approval by Org2MSP...")
+	written, stub, err := approve("Org2MSP")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Org2MSP"}, written.Approvals)
+	assert.Equal(t, "PENDING", written.Status)
+	stub.AssertNotCalled(t, "SetEvent", "RecordUpdated", mock.Anything)
+	record = written
+
+	t.Log("Second approval by Org3MSP reaches the default threshold of 2...")
+	written, stub, err = approve("Org3MSP")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Org2MSP", "Org3MSP"}, written.Approvals)
+	assert.Equal(t, "VERIFIED", written.Status)
+	stub.AssertCalled(t, "SetEvent", "RecordUpdated", mock.Anything)
+	t.Log("The status flipped to VERIFIED only once the threshold was met")
+}
+
+func TestImportedApprovalsAreNotTrusted(t *testing.T) {
+	t.Log("Starting TestImportedApprovalsAreNotTrusted: Verifying forged approvals in an import do not count")
+	contract := new(HistoryContract)
+
+	importCtx, importStub, clientIdentity := newMockContext("Org2MSP")
+	clientIdentity.On("GetAttributeValue", CreatorAttribute).Return(CreatorAttributeValue, true, nil)
+	importStub.On("GetState", compositeKey(configObjectType, configWorkflowGraph)).Return(nil, nil)
+	importStub.On("GetState", compositeKey(configObjectType, configAuthorizedMSPs)).Return(nil, nil)
+	importStub.On("GetState", "IMP001").Return(nil, nil)
+	var imported VerificationRecord
+	importStub.On("PutState", "IMP001", mock.Anything).Run(func(args mock.Arguments) {
+		_ = json.Unmarshal(args.Get(1).([]byte), &imported)
+	}).Return(nil)
+	importStub.On("PutState", mock.Anything, indexEntryValue).Return(nil)
+
+	_, err := contract.BatchImport(importCtx, `[{"id":"IMP001","description":"Imported record","party":"Org2MSP","status":"PENDING","approvals":["Org1MSP"]}]`, "", BatchModeAbort)
+	assert.NoError(t, err)
+	assert.Empty(t, imported.Approvals)
+
+	t.Log("One real approval by Org3MSP stays below the default threshold of 2...")
+	approveCtx, approveStub, _ := newMockContext("Org3MSP")
+	approveStub.On("GetState", "IMP001").Return(mustMarshal(t, imported), nil)
+	approveStub.On("GetState", compositeKey(configObjectType, configApprovalThreshold)).Return(nil, nil)
+	approveStub.On("GetTxTimestamp").Return(timestamppb.Now(), nil)
+	var approved VerificationRecord
+	approveStub.On("PutState", "IMP001", mock.Anything).Run(func(args mock.Arguments) {
+		_ = json.Unmarshal(args.Get(1).([]byte), &approved)
+	}).Return(nil)
+
+	err = contract.ApproveRecord(approveCtx, "IMP001")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Org3MSP"}, approved.Approvals)
+	assert.Equal(t, "PENDING", approved.Status)
+
+	t.Log("The audit still flags the record if it is verified without further sign-offs...")
+	auditCtx, auditStub, _ := newMockContext("Org1MSP")
+	approved.Status = StatusVerified
+	auditStub.On("GetState", "IMP001").Return(mustMarshal(t, approved), nil)
+	auditStub.On("GetState", compositeKey(configObjectType, configApprovalThreshold)).Return(nil, nil)
+	gaps, err := contract.AuditVerifiedSignoffs(auditCtx, `["IMP001"]`)
+	assert.NoError(t, err)
+	assert.Equal(t, []SignoffGap{{ID: "IMP001", MissingApprovers: []string{}, MissingApprovals: 1}}, gaps)
+	t.Log("Only the real approval counted toward verification")
+}
+
+func TestApproveRecordRejectsDoubleApproval(t *testing.T) {
+	ctx, stub, _ := newMockContext("Org2MSP")
+	stub.On("GetState", "REC001").Return(mustMarshal(t, VerificationRecord{ID: "REC001", Status: "PENDING", Approvals: []string{"Org2MSP"}}), nil)
+
+	contract := new(HistoryContract)
+	err := contract.ApproveRecord(ctx, "REC001")
+
+	assert.EqualError(t, err, "organization Org2MSP has already approved record REC001")
+	stub.AssertNotCalled(t, "PutState", mock.Anything, mock.Anything)
+}
+
+func TestRejectRecordRequiresReason(t *testing.T) {
+	t.Log("Starting TestRejectRecordRequiresReason: Verifying a rejection without a reason is refused")
+	ctx, stub, _ := newMockContext("Org1MSP")
+
+	contract := new(HistoryContract)
+	err := contract.RejectRecord(ctx, "REC001", "")
+
+	assert.EqualError(t, err, "a reason is required to reject record REC001")
+	stub.AssertNotCalled(t, "PutState", mock.Anything, mock.Anything)
+}
+
+func TestRejectRecordStoresReason(t *testing.T) {
+	t.Log("Starting TestRejectRecordStoresReason: Verifying the reason is stored and announced")
+	ctx, stub, _ := newMockContext("Org1MSP")
+
+	stub.On("GetState", "REC001").Return(mustMarshal(t, VerificationRecord{ID: "REC001", Party: "Org1MSP", Status: "PENDING"}), nil)
+	stub.On("GetState", compositeKey(configObjectType, configWorkflowGraph)).Return(nil, nil)
+	stub.On("GetTxTimestamp").Return(timestamppb.Now(), nil)
+	var written VerificationRecord
+	stub.On("PutState", "REC001", mock.Anything).Run(func(args mock.Arguments) {
+		_ = json.Unmarshal(args.Get(1).([]byte), &written)
+	}).Return(nil)
+	stub.On("PutState", mock.Anything, indexEntryValue).Return(nil)
+	stub.On("DelState", mock.Anything).Return(nil)
+	var eventPayload []byte
+	stub.On("SetEvent", "RecordRejected", mock.Anything).Run(func(args mock.Arguments) {
+		eventPayload = args.Get(1).([]byte)
+	}).Return(nil)
+
+	contract := new(HistoryContract)
+	err := contract.RejectRecord(ctx, "REC001", "moisture content above limit")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "REJECTED", written.Status)
+	assert.Equal(t, "moisture content above limit", written.RejectionReason)
+	var event RejectionEvent
+	assert.NoError(t, json.Unmarshal(eventPayload, &event))
+	assert.Equal(t, "moisture content above limit", event.Reason)
+	assert.Equal(t, "PENDING", event.OldStatus)
+	t.Log("The reason was stored on the record and carried by the event")
+}
+
+func TestRejectionReasonClearedWhenLeavingRejected(t *testing.T) {
+	t.Log("Starting TestRejectionReasonClearedWhenLeavingRejected: Verifying a stale reason is dropped on write")
+	ctx, stub, _ := newMockContext(AdminMSPID)
+
+	stub.On("GetState", "REC001").Return(nil, nil)
+	var written VerificationRecord
+	stub.On("PutState", "REC001", mock.Anything).Run(func(args mock.Arguments) {
+		_ = json.Unmarshal(args.Get(1).([]byte), &written)
+	}).Return(nil)
+	stub.On("PutState", mock.Anything, indexEntryValue).Return(nil)
+
+	contract := new(HistoryContract)
+	err := contract.putRecord(ctx, &VerificationRecord{ID: "REC001", Status: "PENDING", RejectionReason: "moisture content above limit"})
+
+	assert.NoError(t, err)
+	assert.Empty(t, written.RejectionReason)
+}
+
+func TestProposeAndCommitStatusChange(t *testing.T) {
+	t.Log("Starting TestProposeAndCommitStatusChange: Verifying a proposal is applied once another party commits")
+	contract := new(HistoryContract)
+
+	proposeCtx, proposeStub, _ := newMockContext("Org2MSP")
+	proposeStub.On("GetState", "REC001").Return(mustMarshal(t, VerificationRecord{ID: "REC001", Party: "Org2MSP", Status: "PENDING", Version: 1}), nil)
+	proposeStub.On("GetState", compositeKey(configObjectType, configWorkflowGraph)).Return(nil, nil)
+	proposeStub.On("GetTxTimestamp").Return(timestamppb.Now(), nil)
+	var proposed VerificationRecord
+	proposeStub.On("PutState", "REC001", mock.Anything).Run(func(args mock.Arguments) {
+		_ = json.Unmarshal(args.Get(1).([]byte), &proposed)
+	}).Return(nil)
+
+	err := contract.ProposeStatusChange(proposeCtx, "REC001", "VERIFIED")
+	assert.NoError(t, err)
+	assert.Equal(t, "PENDING", proposed.Status)
+	assert.Equal(t, "VERIFIED", proposed.ProposedStatus)
+	assert.Equal(t, "Org2MSP", proposed.ProposedBy)
+
+	t.Log("Committing the proposal as Org3MSP...")
+	commitCtx, commitStub, _ := newMockContext("Org3MSP")
+	commitStub.On("GetState", "REC001").Return(mustMarshal(t, proposed), nil)
+	commitStub.On("GetState", compositeKey(configObjectType, configWorkflowGraph)).Return(nil, nil)
+	commitStub.On("GetTxTimestamp").Return(timestamppb.Now(), nil)
+	var committed VerificationRecord
+	commitStub.On("PutState", "REC001", mock.Anything).Run(func(args mock.Arguments) {
+		_ = json.Unmarshal(args.Get(1).([]byte), &committed)
+	}).Return(nil)
+	commitStub.On("PutState", mock.Anything, indexEntryValue).Return(nil)
+	commitStub.On("DelState", mock.Anything).Return(nil)
+	commitStub.On("SetEvent", "RecordUpdated", mock.Anything).Return(nil)
+
+	err = contract.CommitStatusChange(commitCtx, "REC001")
+	assert.NoError(t, err)
+	assert.Equal(t, "VERIFIED", committed.Status)
+	assert.Empty(t, committed.ProposedStatus)
+	assert.Empty(t, committed.ProposedBy)
+	assert.Equal(t, "Org2MSP", committed.Party)
+	t.Log("The proposed status was applied and the proposal cleared")
+}
+
+func TestStatusChangeClearsStaleProposal(t *testing.T) {
+	t.Log("Starting TestStatusChangeClearsStaleProposal: Verifying a proposal is dropped once the status moves")
+	contract := new(HistoryContract)
+	record := VerificationRecord{ID: "REC001", Party: "Org1MSP", Status: "CREATED", Version: 1}
+
+	newStub := func() (*MockTransactionContext, *VerificationRecord) {
+		ctx, stub, _ := newMockContext("Org1MSP")
+		stub.On("GetState", "REC001").Return(mustMarshal(t, record), nil)
+		stub.On("GetState", compositeKey(configObjectType, configWorkflowGraph)).Return(nil, nil)
+		stub.On("GetTxTimestamp").Return(timestamppb.Now(), nil)
+		written := &VerificationRecord{}
+		stub.On("PutState", "REC001", mock.Anything).Run(func(args mock.Arguments) {
+			_ = json.Unmarshal(args.Get(1).([]byte), written)
+		}).Return(nil)
+		stub.On("PutState", mock.Anything, indexEntryValue).Return(nil)
+		stub.On("DelState", mock.Anything).Return(nil)
+		stub.On("SetEvent", mock.Anything, mock.Anything).Return(nil)
+		return ctx, written
+	}
+
+	ctx, written := newStub()
+	assert.NoError(t, contract.ProposeStatusChange(ctx, "REC001", "PENDING"))
+	assert.Equal(t, "PENDING", written.ProposedStatus)
+	record = *written
+
+	t.Log("Rejecting the record directly while the proposal is pending...")
+	ctx, written = newStub()
+	assert.NoError(t, contract.RejectRecord(ctx, "REC001", "damaged in transit"))
+	assert.Equal(t, "REJECTED", written.Status)
+	assert.Empty(t, written.ProposedStatus)
+	assert.Empty(t, written.ProposedBy)
+	record = *written
+
+	t.Log("Proposing again from the new status...")
+	ctx, written = newStub()
+	assert.NoError(t, contract.ProposeStatusChange(ctx, "REC001", "ARCHIVED"))
+	assert.Equal(t, "ARCHIVED", written.ProposedStatus)
+	assert.Equal(t, "Org1MSP", written.ProposedBy)
+	t.Log("The stale proposal was cleared and a new one could be made")
+}
+
+func TestCommitStatusChangeRejectsProposer(t *testing.T) {
+	t.Log("Starting TestCommitStatusChangeRejectsProposer: Verifying the proposer cannot commit its own proposal")
+	ctx, stub, _ := newMockContext("Org2MSP")
+
+	contract := new(HistoryContract)
+	stub.On("GetState", "REC001").Return(mustMarshal(t, VerificationRecord{ID: "REC001", Party: "Org2MSP", Status: "PENDING", ProposedStatus: "VERIFIED", ProposedBy: "Org2MSP"}), nil).Once()
+	err := contract.CommitStatusChange(ctx, "REC001")
+	assert.ErrorIs(t, err, ErrUnauthorized)
+	assert.EqualError(t, err, "organization Org2MSP proposed the change to record REC001 and cannot also commit it: unauthorized")
+
+	stub.On("GetState", "REC001").Return(mustMarshal(t, VerificationRecord{ID: "REC001", Party: "Org2MSP", Status: "PENDING"}), nil)
+	err = contract.CommitStatusChange(ctx, "REC001")
+	assert.EqualError(t, err, "record REC001 has no pending status change to commit")
+
+	stub.AssertNotCalled(t, "PutState", mock.Anything, mock.Anything)
+	t.Log("Both the self-commit and the commit without a proposal were refused")
+}
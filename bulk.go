@@ -0,0 +1,164 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// TagRecordsByQuery sets metadata key to value on every record matching the CouchDB selector
+// (e.g. tag all {"status":"PENDING"} records with "review_batch"). Because of its reach it is admin only.
+// Sealed and locked records are left untouched. It returns the number of records tagged.
+func (s *HistoryContract) TagRecordsByQuery(ctx contractapi.TransactionContextInterface, selectorJSON string, key string, value string) (int, error) {
+	if err := requireAdmin(ctx); err != nil {
+		return 0, err
+	}
+	if key == "" {
+		return 0, fmt.Errorf("metadata key must not be empty")
+	}
+	if value == "" {
+		return 0, fmt.Errorf("metadata value must not be empty")
+	}
+
+	selector, err := parseSelector(selectorJSON)
+	if err != nil {
+		return 0, err
+	}
+
+	records, err := s.queryRecords(ctx, selector)
+	if err != nil {
+		return 0, err
+	}
+
+	timestamp, err := txTimestampString(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	tagged := 0
+	for _, record := range records {
+		// Sealed and locked records must not change, so they keep their metadata as it was
+		if ensureMutable(record) != nil {
+			continue
+		}
+		if record.Metadata == nil {
+			record.Metadata = make(map[string]string)
+		}
+		record.Metadata[key] = value
+		record.Timestamp = timestamp
+
+		if err := s.putRecord(ctx, record); err != nil {
+			return 0, err
+		}
+		tagged++
+	}
+
+	return tagged, nil
+}
+
+// ArchiveByQuery moves every record matching the CouchDB selector to ARCHIVED (admin only), e.g.
+// {"status":"VERIFIED"} for end-of-period cleanup. Sealed, locked and already archived records are skipped,
+// as are records the workflow does not allow to move to ARCHIVED. It returns the number of records archived.
+func (s *HistoryContract) ArchiveByQuery(ctx contractapi.TransactionContextInterface, selectorJSON string) (int, error) {
+	if err := requireAdmin(ctx); err != nil {
+		return 0, err
+	}
+
+	selector, err := parseSelector(selectorJSON)
+	if err != nil {
+		return 0, err
+	}
+
+	records, err := s.queryRecords(ctx, selector)
+	if err != nil {
+		return 0, err
+	}
+
+	timestamp, err := txTimestampString(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	archived := 0
+	for _, record := range records {
+		if record.Status == StatusArchived || ensureMutable(record) != nil {
+			continue
+		}
+		allowed, err := isValidTransition(ctx, record.Status, StatusArchived)
+		if err != nil {
+			return 0, err
+		}
+		if !allowed {
+			continue
+		}
+
+		record.Status = StatusArchived
+		record.Timestamp = timestamp
+		if err := s.putRecord(ctx, record); err != nil {
+			return 0, err
+		}
+		archived++
+	}
+
+	return archived, nil
+}
+
+// BulkUpdateStatus moves each listed record to newStatus in one transaction. Every record is checked on its
+// own (ownership and workflow transition) and problems are collected in the report's Failed map instead of
+// aborting; records already in newStatus are reported as skipped. Owners are left unchanged. No per-record
+// events are emitted because Fabric keeps only the last event set in a transaction.
+func (s *HistoryContract) BulkUpdateStatus(ctx contractapi.TransactionContextInterface, ids []string, newStatus string) (*BatchImportReport, error) {
+	if newStatus == "" {
+		return nil, fmt.Errorf("new status must not be empty")
+	}
+	if err := requireKnownStatus(ctx, newStatus); err != nil {
+		return nil, err
+	}
+	if len(ids) > maxBatchSize {
+		return nil, fmt.Errorf("batch size %d exceeds maximum %d", len(ids), maxBatchSize)
+	}
+
+	timestamp, err := txTimestampString(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &BatchImportReport{Imported: []string{}, Skipped: []string{}, Failed: map[string]string{}}
+	for _, id := range ids {
+		record, err := s.readRecord(ctx, id)
+		if err != nil {
+			report.Failed[id] = err.Error()
+			continue
+		}
+		if record.Status == newStatus {
+			report.Skipped = append(report.Skipped, id)
+			continue
+		}
+		if err := ensureMutable(record); err != nil {
+			report.Failed[id] = err.Error()
+			continue
+		}
+		if _, err := authorizeOwner(ctx, record, "update"); err != nil {
+			report.Failed[id] = err.Error()
+			continue
+		}
+
+		allowed, err := isValidTransition(ctx, record.Status, newStatus)
+		if err != nil {
+			return nil, err
+		}
+		if !allowed {
+			report.Failed[id] = fmt.Sprintf("invalid status transition from %s to %s", record.Status, newStatus)
+			continue
+		}
+
+		record.Status = newStatus
+		record.Timestamp = timestamp
+		if err := s.putRecord(ctx, record); err != nil {
+			return nil, err
+		}
+		report.Imported = append(report.Imported, id)
+	}
+
+	return report, nil
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestTagRecordsByQuery(t *testing.T) {
+	t.Log("Starting TestTagRecordsByQuery: Verifying every matched record is tagged")
+	ctx, stub, _ := newMockContext(AdminMSPID)
+
+	matched := []VerificationRecord{
+		{ID: "REC001", Status: "PENDING"},
+		{ID: "REC002", Status: "PENDING", Metadata: map[string]string{"location": "Thika"}},
+		{ID: "REC003", Status: "PENDING", Locked: true},
+	}
+	stub.On("GetQueryResult", `{"selector":{"status":"PENDING"}}`).Return(newStateIterator(t, matched...), nil)
+	stub.On("GetTxTimestamp").Return(timestamppb.New(time.Date(2024, 3, 1, 8, 0, 0, 0, time.UTC)), nil)
+	for i := range matched {
+		stub.On("GetState", matched[i].ID).Return(mustMarshal(t, &matched[i]), nil)
+	}
+
+	written := make(map[string]VerificationRecord)
+	stub.On("PutState", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		var record VerificationRecord
+		_ = json.Unmarshal(args.Get(1).([]byte), &record)
+		written[args.String(0)] = record
+	}).Return(nil)
+
+	contract := new(HistoryContract)
+	tagged, err := contract.TagRecordsByQuery(ctx, `{"status":"PENDING"}`, "review_batch", "Q1")
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, tagged)
+	assert.Equal(t, map[string]string{"review_batch": "Q1"}, written["REC001"].Metadata)
+	assert.Equal(t, map[string]string{"location": "Thika", "review_batch": "Q1"}, written["REC002"].Metadata)
+	assert.Equal(t, "2024-03-01T08:00:00Z", written["REC001"].Timestamp)
+	assert.NotContains(t, written, "REC003")
+	t.Log("Both unlocked PENDING records were tagged, existing metadata was kept and the locked one skipped")
+}
+
+func TestTagRecordsByQueryRequiresAdmin(t *testing.T) {
+	ctx, stub, _ := newMockContext("Org2MSP")
+
+	contract := new(HistoryContract)
+	_, err := contract.TagRecordsByQuery(ctx, `{"status":"PENDING"}`, "review_batch", "Q1")
+
+	assert.Error(t, err)
+	stub.AssertNotCalled(t, "GetQueryResult", mock.Anything)
+}
+
+func TestArchiveByQuerySkipsSealed(t *testing.T) {
+	t.Log("Starting TestArchiveByQuerySkipsSealed: Verifying matched records are archived except sealed ones")
+	ctx, stub, _ := newMockContext(AdminMSPID)
+
+	matched := []VerificationRecord{
+		{ID: "REC001", Status: "VERIFIED"},
+		{ID: "REC002", Status: "VERIFIED"},
+		{ID: "REC003", Status: "VERIFIED", Sealed: true},
+		{ID: "REC004", Status: "VERIFIED", Locked: true},
+		{ID: "REC005", Status: "CREATED"},
+	}
+	stub.On("GetQueryResult", `{"selector":{"status":"VERIFIED"}}`).Return(newStateIterator(t, matched...), nil)
+	stub.On("GetState", compositeKey(configObjectType, configWorkflowGraph)).Return(nil, nil)
+	stub.On("GetTxTimestamp").Return(timestamppb.Now(), nil)
+	for i := range matched {
+		stub.On("GetState", matched[i].ID).Return(mustMarshal(t, &matched[i]), nil)
+	}
+	stub.On("DelState", mock.Anything).Return(nil)
+
+	written := make(map[string]VerificationRecord)
+	stub.On("PutState", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		var record VerificationRecord
+		_ = json.Unmarshal(args.Get(1).([]byte), &record)
+		written[args.String(0)] = record
+	}).Return(nil)
+
+	contract := new(HistoryContract)
+	archived, err := contract.ArchiveByQuery(ctx, `{"status":"VERIFIED"}`)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, archived)
+	assert.Equal(t, StatusArchived, written["REC001"].Status)
+	assert.Equal(t, StatusArchived, written["REC002"].Status)
+	assert.NotContains(t, written, "REC003")
+	assert.NotContains(t, written, "REC004")
+	assert.NotContains(t, written, "REC005")
+	t.Log("Two records were archived; the sealed, locked and not yet archivable records were left untouched")
+}
+
+func TestBulkUpdateStatusWithMissingID(t *testing.T) {
+	t.Log("Starting TestBulkUpdateStatusWithMissingID: Verifying a missing ID is reported while the rest are updated")
+	ctx, stub, _ := newMockContext(AdminMSPID)
+
+	stub.On("GetTxTimestamp").Return(timestamppb.Now(), nil)
+	stub.On("GetState", "REC001").Return(mustMarshal(t, VerificationRecord{ID: "REC001", Party: "Org2MSP", Status: "PENDING"}), nil)
+	stub.On("GetState", "REC002").Return(mustMarshal(t, VerificationRecord{ID: "REC002", Party: "Org3MSP", Status: "CREATED"}), nil)
+	stub.On("GetState", "REC404").Return(nil, nil)
+	stub.On("GetState", compositeKey(configObjectType, configWorkflowGraph)).Return(nil, nil)
+	stub.On("PutState", mock.Anything, mock.Anything).Return(nil)
+	stub.On("DelState", mock.Anything).Return(nil)
+
+	contract := new(HistoryContract)
+	report, err := contract.BulkUpdateStatus(ctx, []string{"REC001", "REC404", "REC002"}, "REJECTED")
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"REC001", "REC002"}, report.Imported)
+	assert.Equal(t, map[string]string{"REC404": "record REC404 does not exist: record not found"}, report.Failed)
+	stub.AssertCalled(t, "PutState", "REC001", mock.Anything)
+	stub.AssertCalled(t, "PutState", "REC002", mock.Anything)
+	t.Log("Both existing records were rejected and the missing one was reported")
+}
@@ -0,0 +1,271 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// configObjectType prefixes every configuration key. Config entries are composite keys, so they
+// never show up in plain range scans over the records.
+const configObjectType = "config"
+
+// Configuration entry names
+const (
+	configDefaultPageSize   = "defaultPageSize"
+	configWorkflowGraph     = "workflowGraph"
+	configPartyName         = "partyName" // Qualified by display name, value is the MSP ID
+	configMerkleRoot        = "merkleRoot"
+	configApprovalThreshold = "approvalThreshold"
+	configAuthorizedMSPs    = "authorizedMSPs"
+)
+
+// defaultApprovalThreshold is the number of distinct approvals that verify a record when none is configured
+const defaultApprovalThreshold = 2
+
+// Page size bounds used by the paginated queries
+const (
+	fallbackPageSize int32 = 100  // Used when no default page size has been configured
+	maxPageSize      int32 = 1000 // Hard upper bound for any page or chunk
+)
+
+// SetDefaultPageSize sets the page size used when callers pass 0 to a paginated query (admin only).
+// Sizes above the hard maximum are clamped.
+func (s *HistoryContract) SetDefaultPageSize(ctx contractapi.TransactionContextInterface, size int32) error {
+	if err := requireAdmin(ctx); err != nil {
+		return err
+	}
+	if size <= 0 {
+		return fmt.Errorf("default page size must be greater than zero, got %d", size)
+	}
+	if size > maxPageSize {
+		size = maxPageSize
+	}
+
+	return putConfigValue(ctx, configDefaultPageSize, strconv.Itoa(int(size)))
+}
+
+// GetConfig returns every configuration entry currently stored on the ledger, keyed by name.
+// None of the configuration is secret, so values are returned as stored.
+func (s *HistoryContract) GetConfig(ctx contractapi.TransactionContextInterface) (map[string]string, error) {
+	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(configObjectType, []string{})
+	if err != nil {
+		return nil, err
+	}
+	defer resultsIterator.Close()
+
+	config := make(map[string]string)
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+
+		_, attributes, err := ctx.GetStub().SplitCompositeKey(queryResponse.Key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to split config key: %v", err)
+		}
+		config[strings.Join(attributes, ".")] = string(queryResponse.Value)
+	}
+
+	return config, nil
+}
+
+// ResetConfig deletes every configuration entry (admin only) so the chaincode reverts to its
+// built-in defaults. It returns the number of entries removed.
+func (s *HistoryContract) ResetConfig(ctx contractapi.TransactionContextInterface) (int, error) {
+	if err := requireAdmin(ctx); err != nil {
+		return 0, err
+	}
+
+	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(configObjectType, []string{})
+	if err != nil {
+		return 0, err
+	}
+	defer resultsIterator.Close()
+
+	removed := 0
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return 0, err
+		}
+
+		if err := ctx.GetStub().DelState(queryResponse.Key); err != nil {
+			return 0, fmt.Errorf("failed to delete config entry: %v", err)
+		}
+		removed++
+	}
+
+	return removed, nil
+}
+
+// SetApprovalThreshold sets how many distinct organizations must approve a record before
+// ApproveRecord verifies it (admin only). Records with required approvers ignore the threshold.
+func (s *HistoryContract) SetApprovalThreshold(ctx contractapi.TransactionContextInterface, threshold int) error {
+	if err := requireAdmin(ctx); err != nil {
+		return err
+	}
+	if threshold <= 0 {
+		return fmt.Errorf("approval threshold must be greater than zero, got %d", threshold)
+	}
+
+	return putConfigValue(ctx, configApprovalThreshold, strconv.Itoa(threshold))
+}
+
+// getApprovalThreshold returns the configured approval threshold or the default
+func getApprovalThreshold(ctx contractapi.TransactionContextInterface) (int, error) {
+	value, found, err := getConfigValue(ctx, configApprovalThreshold)
+	if err != nil {
+		return 0, err
+	}
+	if !found {
+		return defaultApprovalThreshold, nil
+	}
+
+	threshold, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s configuration %q: %v", configApprovalThreshold, value, err)
+	}
+	return threshold, nil
+}
+
+// SetAuthorizedMSPs replaces the organizations allowed to create records (admin only), so membership changes
+// need no chaincode upgrade. The list is stored as a config entry and must name at least one MSP.
+func (s *HistoryContract) SetAuthorizedMSPs(ctx contractapi.TransactionContextInterface, msps []string) error {
+	if err := requireAdmin(ctx); err != nil {
+		return err
+	}
+	if len(msps) == 0 {
+		return fmt.Errorf("authorized MSP list must not be empty")
+	}
+
+	authorized := []string{}
+	for _, msp := range msps {
+		if strings.TrimSpace(msp) == "" {
+			return fmt.Errorf("authorized MSP IDs must not be empty")
+		}
+		if !slices.Contains(authorized, msp) {
+			authorized = append(authorized, msp)
+		}
+	}
+
+	value, err := json.Marshal(authorized)
+	if err != nil {
+		return err
+	}
+	return putConfigValue(ctx, configAuthorizedMSPs, string(value))
+}
+
+// GetAuthorizedMSPs returns the organizations allowed to create records.
+// An empty list means no restriction has been configured and every organization may create.
+func (s *HistoryContract) GetAuthorizedMSPs(ctx contractapi.TransactionContextInterface) ([]string, error) {
+	value, found, err := getConfigValue(ctx, configAuthorizedMSPs)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return []string{}, nil
+	}
+
+	var msps []string
+	if err := json.Unmarshal([]byte(value), &msps); err != nil {
+		return nil, fmt.Errorf("invalid %s configuration: %v", configAuthorizedMSPs, err)
+	}
+	return msps, nil
+}
+
+// requireAuthorizedCreator rejects organizations missing from a configured authorized MSP list
+func (s *HistoryContract) requireAuthorizedCreator(ctx contractapi.TransactionContextInterface, mspID string) error {
+	msps, err := s.GetAuthorizedMSPs(ctx)
+	if err != nil {
+		return err
+	}
+	if len(msps) > 0 && !slices.Contains(msps, mspID) {
+		return fmt.Errorf("organization %s is not authorized to create records: %w", mspID, ErrUnauthorized)
+	}
+	return nil
+}
+
+// resolvePageSize applies the configured default to a zero page size and clamps large ones
+func resolvePageSize(ctx contractapi.TransactionContextInterface, requested int32) (int32, error) {
+	if requested < 0 {
+		return 0, fmt.Errorf("page size must not be negative, got %d", requested)
+	}
+
+	if requested == 0 {
+		value, found, err := getConfigValue(ctx, configDefaultPageSize)
+		if err != nil {
+			return 0, err
+		}
+		requested = fallbackPageSize
+		if found {
+			configured, err := strconv.ParseInt(value, 10, 32)
+			if err != nil {
+				return 0, fmt.Errorf("invalid %s configuration %q: %v", configDefaultPageSize, value, err)
+			}
+			requested = int32(configured)
+		}
+	}
+
+	if requested > maxPageSize {
+		requested = maxPageSize
+	}
+	return requested, nil
+}
+
+// requireAdmin rejects callers that are not the admin MSP
+func requireAdmin(ctx contractapi.TransactionContextInterface) error {
+	clientIdentity, err := ctx.GetClientIdentity().GetMSPID()
+	if err != nil {
+		return fmt.Errorf("failed to get client identity: %v", err)
+	}
+	if !isAdmin(clientIdentity) {
+		return fmt.Errorf("caller %s is not authorized, only %s may change the configuration: %w", clientIdentity, AdminMSPID, ErrUnauthorized)
+	}
+	return nil
+}
+
+// configKey returns the world state key of a configuration entry.
+// Qualifiers scope an entry further, e.g. one "partyName" entry per display name.
+func configKey(ctx contractapi.TransactionContextInterface, name string, qualifiers ...string) (string, error) {
+	key, err := ctx.GetStub().CreateCompositeKey(configObjectType, append([]string{name}, qualifiers...))
+	if err != nil {
+		return "", fmt.Errorf("failed to create config key for %s: %v", name, err)
+	}
+	return key, nil
+}
+
+// getConfigValue reads a configuration entry, reporting whether it has been set
+func getConfigValue(ctx contractapi.TransactionContextInterface, name string, qualifiers ...string) (string, bool, error) {
+	key, err := configKey(ctx, name, qualifiers...)
+	if err != nil {
+		return "", false, err
+	}
+
+	value, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return "", false, fmt.Errorf("failed to read config %s: %v", name, err)
+	}
+	if value == nil {
+		return "", false, nil
+	}
+	return string(value), true, nil
+}
+
+// putConfigValue stores a configuration entry
+func putConfigValue(ctx contractapi.TransactionContextInterface, name string, value string, qualifiers ...string) error {
+	key, err := configKey(ctx, name, qualifiers...)
+	if err != nil {
+		return err
+	}
+
+	if err := ctx.GetStub().PutState(key, []byte(value)); err != nil {
+		return fmt.Errorf("failed to write config %s: %v", name, err)
+	}
+	return nil
+}
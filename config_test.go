@@ -0,0 +1,195 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric-protos-go/ledger/queryresult"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestZeroPageSizeFallsBackToConfiguredDefault(t *testing.T) {
+	t.Log("Starting TestZeroPageSizeFallsBackToConfiguredDefault: Verifying the configured default page size is used")
+	ctx, stub, _ := newMockContext(AdminMSPID)
+
+	pageSizeKey := compositeKey(configObjectType, configDefaultPageSize)
+	stub.On("PutState", pageSizeKey, []byte("2")).Return(nil)
+
+	t.Log("Setting the default page size to 2 as admin...")
+	contract := new(HistoryContract)
+	err := contract.SetDefaultPageSize(ctx, 2)
+	assert.NoError(t, err)
+	stub.AssertExpectations(t)
+
+	records := []VerificationRecord{{ID: "REC001"}, {ID: "REC002"}, {ID: "REC003"}}
+	stub.On("GetState", pageSizeKey).Return([]byte("2"), nil)
+	stub.On("GetStateByRange", "", "").Return(newStateIterator(t, records...), nil)
+
+	t.Log("Reading a chunk with a zero chunk size...")
+	chunk, err := contract.GetRecordsChunk(ctx, "", 0)
+
+	assert.NoError(t, err)
+	assert.Len(t, chunk.Records, 2)
+	assert.Equal(t, "REC003", chunk.NextStartKey)
+	t.Log("The zero chunk size fell back to the configured default of 2")
+}
+
+func TestSetDefaultPageSizeClampsAndRequiresAdmin(t *testing.T) {
+	t.Log("Starting TestSetDefaultPageSizeClampsAndRequiresAdmin: Verifying clamping and admin enforcement")
+	ctx, stub, _ := newMockContext(AdminMSPID)
+	stub.On("PutState", compositeKey(configObjectType, configDefaultPageSize), []byte("1000")).Return(nil)
+
+	contract := new(HistoryContract)
+	assert.NoError(t, contract.SetDefaultPageSize(ctx, 50000))
+	stub.AssertExpectations(t)
+
+	otherCtx, otherStub, _ := newMockContext("Org2MSP")
+	err := contract.SetDefaultPageSize(otherCtx, 10)
+	assert.EqualError(t, err, "caller Org2MSP is not authorized, only Org1MSP may change the configuration: unauthorized")
+	otherStub.AssertNotCalled(t, "PutState", mock.Anything, mock.Anything)
+}
+
+func TestGetConfig(t *testing.T) {
+	t.Log("Starting TestGetConfig: Verifying all configuration entries are listed")
+	ctx, stub, _ := newMockContext("Org2MSP")
+
+	stub.On("GetStateByPartialCompositeKey", configObjectType, []string{}).Return(newKVIterator(
+		&queryresult.KV{Key: compositeKey(configObjectType, configDefaultPageSize), Value: []byte("25")},
+		&queryresult.KV{Key: compositeKey(configObjectType, configWorkflowGraph), Value: []byte(`{"CREATED":[]}`)},
+	), nil)
+
+	contract := new(HistoryContract)
+	config, err := contract.GetConfig(ctx)
+
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		configDefaultPageSize: "25",
+		configWorkflowGraph:   `{"CREATED":[]}`,
+	}, config)
+	t.Log("Both configuration entries were returned by name")
+}
+
+func TestResetConfigRestoresDefaults(t *testing.T) {
+	t.Log("Starting TestResetConfigRestoresDefaults: Verifying config entries are deleted and defaults apply")
+	ctx, stub, _ := newMockContext(AdminMSPID)
+
+	pageSizeKey := compositeKey(configObjectType, configDefaultPageSize)
+	graphKey := compositeKey(configObjectType, configWorkflowGraph)
+	stub.On("GetStateByPartialCompositeKey", configObjectType, []string{}).Return(newKVIterator(
+		&queryresult.KV{Key: pageSizeKey, Value: []byte("25")},
+		&queryresult.KV{Key: graphKey, Value: []byte(`{"CREATED":[]}`)},
+	), nil)
+	stub.On("DelState", pageSizeKey).Return(nil)
+	stub.On("DelState", graphKey).Return(nil)
+
+	contract := new(HistoryContract)
+	removed, err := contract.ResetConfig(ctx)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, removed)
+	stub.AssertExpectations(t)
+
+	t.Log("Confirming the built-in defaults apply once the entries are gone...")
+	stub.On("GetState", pageSizeKey).Return(nil, nil)
+	stub.On("GetState", graphKey).Return(nil, nil)
+
+	pageSize, err := resolvePageSize(ctx, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, fallbackPageSize, pageSize)
+
+	graph, err := getWorkflowGraph(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, AllowedTransitions, graph)
+	t.Log("The default page size and workflow were restored")
+}
+
+func TestResetConfigRequiresAdmin(t *testing.T) {
+	ctx, stub, _ := newMockContext("Org3MSP")
+
+	contract := new(HistoryContract)
+	_, err := contract.ResetConfig(ctx)
+
+	assert.Error(t, err)
+	stub.AssertNotCalled(t, "DelState", mock.Anything)
+}
+
+func TestSetAuthorizedMSPs(t *testing.T) {
+	t.Log("Starting TestSetAuthorizedMSPs: Verifying the admin stores the list under the config key")
+	ctx, stub, _ := newMockContext(AdminMSPID)
+
+	// Config entries are composite keys, which GetAllRecords' plain range scan never returns
+	listKey := compositeKey(configObjectType, configAuthorizedMSPs)
+	stub.On("PutState", listKey, []byte(`["Org1MSP","Org2MSP"]`)).Return(nil)
+
+	contract := new(HistoryContract)
+	err := contract.SetAuthorizedMSPs(ctx, []string{"Org1MSP", "Org2MSP", "Org1MSP"})
+	assert.NoError(t, err)
+	stub.AssertExpectations(t)
+
+	stub.On("GetState", listKey).Return([]byte(`["Org1MSP","Org2MSP"]`), nil)
+	msps, err := contract.GetAuthorizedMSPs(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Org1MSP", "Org2MSP"}, msps)
+	t.Log("The deduplicated list was stored and read back")
+}
+
+func TestSetAuthorizedMSPsRequiresAdmin(t *testing.T) {
+	ctx, stub, _ := newMockContext("Org2MSP")
+
+	contract := new(HistoryContract)
+	err := contract.SetAuthorizedMSPs(ctx, []string{"Org2MSP"})
+
+	assert.ErrorIs(t, err, ErrUnauthorized)
+	stub.AssertNotCalled(t, "PutState", mock.Anything, mock.Anything)
+}
+
+func TestCreateRecordEnforcesAuthorizedMSPs(t *testing.T) {
+	t.Log("Starting TestCreateRecordEnforcesAuthorizedMSPs: Verifying organizations outside the list cannot create")
+	ctx, stub, clientIdentity := newMockContext("Org3MSP")
+	clientIdentity.On("GetAttributeValue", CreatorAttribute).Return(CreatorAttributeValue, true, nil)
+	stub.On("GetState", compositeKey(configObjectType, configWorkflowGraph)).Return(nil, nil)
+	stub.On("GetState", compositeKey(configObjectType, configAuthorizedMSPs)).Return([]byte(`["Org1MSP","Org2MSP"]`), nil)
+	stub.On("GetState", "REC001").Return(nil, nil)
+
+	contract := new(HistoryContract)
+	err := contract.CreateRecord(ctx, "REC001", "Initial Draft", "CREATED", "", 0, "")
+
+	assert.EqualError(t, err, "organization Org3MSP is not authorized to create records: unauthorized")
+	stub.AssertNotCalled(t, "PutState", mock.Anything, mock.Anything)
+}
+
+func TestImportsEnforceAuthorizedMSPs(t *testing.T) {
+	t.Log("Starting TestImportsEnforceAuthorizedMSPs: Verifying imports and private creates honor the list")
+	contract := new(HistoryContract)
+	data := `[{"id":"IMP001","description":"Imported record","status":"CREATED"}]`
+
+	creates := map[string]func(ctx *MockTransactionContext) error{
+		"BatchImport": func(ctx *MockTransactionContext) error {
+			_, err := contract.BatchImport(ctx, data, "", BatchModeAbort)
+			return err
+		},
+		"BatchImportCSV": func(ctx *MockTransactionContext) error {
+			_, err := contract.BatchImportCSV(ctx, "id,description,status\nIMP001,Imported record,CREATED\n")
+			return err
+		},
+		"CreatePrivateRecord": func(ctx *MockTransactionContext) error {
+			return contract.CreatePrivateRecord(ctx, "IMP001")
+		},
+	}
+
+	for name, create := range creates {
+		ctx, stub, clientIdentity := newMockContext("Org3MSP")
+		clientIdentity.On("GetAttributeValue", CreatorAttribute).Return(CreatorAttributeValue, true, nil)
+		stub.On("GetState", compositeKey(configObjectType, configWorkflowGraph)).Return(nil, nil)
+		stub.On("GetState", compositeKey(configObjectType, configAuthorizedMSPs)).Return([]byte(`["Org1MSP","Org2MSP"]`), nil)
+		stub.On("GetState", "IMP001").Return(nil, nil)
+		stub.On("GetTransient").Return(map[string][]byte{"record": []byte(`{"id":"IMP001","description":"Private terms","status":"CREATED"}`)}, nil)
+
+		err := create(ctx)
+
+		assert.EqualError(t, err, "organization Org3MSP is not authorized to create records: unauthorized", name)
+		stub.AssertNotCalled(t, "PutState", mock.Anything, mock.Anything)
+		stub.AssertNotCalled(t, "PutPrivateData", mock.Anything, mock.Anything, mock.Anything)
+	}
+	t.Log("Every create path refused the unlisted organization")
+}
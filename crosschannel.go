@@ -0,0 +1,140 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// ChannelTarget identifies a chaincode deployed on another channel
+type ChannelTarget struct {
+	Channel   string `json:"channel"`
+	Chaincode string `json:"chaincode"`
+}
+
+// PathVerification is the outcome of checking a record along a chain of channels.
+// When Consistent is false, FailedHop is the zero-based index of the first hop that disagreed.
+type PathVerification struct {
+	Consistent bool   `json:"consistent"`
+	FailedHop  int    `json:"failedHop"`
+	Reason     string `json:"reason"`
+}
+
+// VerifyAcrossChannelPath reads the same record from each hop of pathJSON, an ordered array such as
+// [{"channel":"intake","chaincode":"thikachain"},{"channel":"export","chaincode":"thikachain"}],
+// and confirms every hop holds the same business content as the first one. Ledger bookkeeping such as
+// Version, Hash, PrevHash, timestamps and BatchID differs once a record is re-imported on another channel,
+// so only the fields listed in recordBusinessFields are compared. Checking stops at the first inconsistency.
+// Like QueryOtherLedger, this only reads from the other channels.
+func (s *HistoryContract) VerifyAcrossChannelPath(ctx contractapi.TransactionContextInterface, pathJSON string, id string) (*PathVerification, error) {
+	var path []ChannelTarget
+	if err := json.Unmarshal([]byte(pathJSON), &path); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal channel path: %v", err)
+	}
+	if len(path) < 2 {
+		return nil, fmt.Errorf("channel path must contain at least two hops, got %d", len(path))
+	}
+
+	reference := ""
+	for hop, target := range path {
+		if target.Channel == "" || target.Chaincode == "" {
+			return nil, fmt.Errorf("hop %d must name both a channel and a chaincode", hop)
+		}
+
+		record, err := s.queryRecordOnChannel(ctx, target, id)
+		if err != nil {
+			return &PathVerification{FailedHop: hop, Reason: err.Error()}, nil
+		}
+
+		content, err := businessContent(record)
+		if err != nil {
+			return nil, err
+		}
+
+		if hop == 0 {
+			reference = content
+			continue
+		}
+		if content != reference {
+			return &PathVerification{
+				FailedHop: hop,
+				Reason:    fmt.Sprintf("record %s on channel %s differs from channel %s", id, target.Channel, path[0].Channel),
+			}, nil
+		}
+	}
+
+	return &PathVerification{Consistent: true, FailedHop: -1}, nil
+}
+
+// recordBusinessFields is the part of a record that must survive propagation to another channel unchanged
+type recordBusinessFields struct {
+	ID           string            `json:"id"`
+	Description  string            `json:"description"`
+	Party        string            `json:"party"`
+	Status       string            `json:"status"`
+	Quantity     int64             `json:"quantity"`
+	Category     string            `json:"category"`
+	Metadata     map[string]string `json:"metadata,omitempty"`
+	DocumentHash string            `json:"documentHash"`
+}
+
+// businessContent returns the canonical JSON of the record's business fields for comparison across channels.
+// Map keys are sorted by encoding/json, and an empty Metadata map encodes the same as a missing one.
+func businessContent(record *VerificationRecord) (string, error) {
+	content, err := json.Marshal(recordBusinessFields{
+		ID:           record.ID,
+		Description:  record.Description,
+		Party:        record.Party,
+		Status:       record.Status,
+		Quantity:     record.Quantity,
+		Category:     record.Category,
+		Metadata:     record.Metadata,
+		DocumentHash: record.DocumentHash,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal record %s for comparison: %v", record.ID, err)
+	}
+	return string(content), nil
+}
+
+// QueryMultipleLedgers calls functionName with arg on every target and returns the payloads keyed by channel.
+// A channel that fails does not abort the others; its entry holds "error: " followed by the failure instead.
+func (s *HistoryContract) QueryMultipleLedgers(ctx contractapi.TransactionContextInterface, targets []ChannelTarget, functionName string, arg string) (map[string]string, error) {
+	if len(targets) == 0 {
+		return nil, fmt.Errorf("at least one channel target is required")
+	}
+
+	results := make(map[string]string, len(targets))
+	for i, target := range targets {
+		if target.Channel == "" || target.Chaincode == "" {
+			return nil, fmt.Errorf("target %d must name both a channel and a chaincode", i)
+		}
+		if _, seen := results[target.Channel]; seen {
+			return nil, fmt.Errorf("duplicate channel %s in targets", target.Channel)
+		}
+
+		payload, err := s.QueryOtherLedger(ctx, target.Channel, target.Chaincode, functionName, arg)
+		if err != nil {
+			results[target.Channel] = "error: " + err.Error()
+			continue
+		}
+		results[target.Channel] = payload
+	}
+
+	return results, nil
+}
+
+// queryRecordOnChannel reads a record through the target chaincode's GetRecord transaction
+func (s *HistoryContract) queryRecordOnChannel(ctx contractapi.TransactionContextInterface, target ChannelTarget, id string) (*VerificationRecord, error) {
+	payload, err := s.QueryOtherLedger(ctx, target.Channel, target.Chaincode, "GetRecord", id)
+	if err != nil {
+		return nil, fmt.Errorf("channel %s: %v", target.Channel, err)
+	}
+
+	var record VerificationRecord
+	if err := json.Unmarshal([]byte(payload), &record); err != nil {
+		return nil, fmt.Errorf("channel %s returned an unreadable record %s: %v", target.Channel, id, err)
+	}
+	return &record, nil
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestVerifyAcrossChannelPathConsistent(t *testing.T) {
+	t.Log("Starting TestVerifyAcrossChannelPathConsistent: Verifying matching records on two channels pass")
+	ctx, stub, _ := newMockContext("Org1MSP")
+
+	record := &VerificationRecord{ID: "REC001", Description: "Grade A tea", Party: "Org1MSP", Status: "VERIFIED",
+		Metadata: map[string]string{"Location": "Thika"}, Version: 3, Timestamp: "2024-01-01T00:00:00Z", BatchID: "B1"}
+	// The re-imported copy only differs in ledger bookkeeping
+	reimported := *record
+	reimported.Version = 1
+	reimported.Timestamp = "2024-02-01T00:00:00Z"
+	reimported.CreatedAt = "2024-02-01T00:00:00Z"
+	reimported.BatchID = "B7"
+	reimported.Hash = "abc"
+	reimported.PrevHash = "def"
+	getRecordArgs := [][]byte{[]byte("GetRecord"), []byte("REC001")}
+	stub.On("InvokeChaincode", "thikachain", getRecordArgs, "intake").Return(peer.Response{Status: 200, Payload: mustMarshal(t, record)})
+	stub.On("InvokeChaincode", "thikachain", getRecordArgs, "export").Return(peer.Response{Status: 200, Payload: mustMarshal(t, &reimported)})
+
+	contract := new(HistoryContract)
+	path := `[{"channel":"intake","chaincode":"thikachain"},{"channel":"export","chaincode":"thikachain"}]`
+	result, err := contract.VerifyAcrossChannelPath(ctx, path, "REC001")
+
+	assert.NoError(t, err)
+	assert.Equal(t, &PathVerification{Consistent: true, FailedHop: -1}, result)
+	stub.AssertExpectations(t)
+	t.Log("Record was consistent across both channels")
+}
+
+func TestVerifyAcrossChannelPathDetectsChangedContent(t *testing.T) {
+	ctx, stub, _ := newMockContext("Org1MSP")
+
+	record := &VerificationRecord{ID: "REC001", Description: "Grade A tea", Party: "Org1MSP", Status: "VERIFIED"}
+	changed := *record
+	changed.Quantity = 40
+	getRecordArgs := [][]byte{[]byte("GetRecord"), []byte("REC001")}
+	stub.On("InvokeChaincode", "thikachain", getRecordArgs, "intake").Return(peer.Response{Status: 200, Payload: mustMarshal(t, record)})
+	stub.On("InvokeChaincode", "thikachain", getRecordArgs, "export").Return(peer.Response{Status: 200, Payload: mustMarshal(t, record)})
+	stub.On("InvokeChaincode", "thikachain", getRecordArgs, "retail").Return(peer.Response{Status: 200, Payload: mustMarshal(t, &changed)})
+
+	contract := new(HistoryContract)
+	path := `[{"channel":"intake","chaincode":"thikachain"},{"channel":"export","chaincode":"thikachain"},{"channel":"retail","chaincode":"thikachain"}]`
+	result, err := contract.VerifyAcrossChannelPath(ctx, path, "REC001")
+
+	assert.NoError(t, err)
+	assert.False(t, result.Consistent)
+	assert.Equal(t, 2, result.FailedHop)
+	assert.Equal(t, "record REC001 on channel retail differs from channel intake", result.Reason)
+}
+
+func TestQueryOtherLedgerRetriesTransientFailure(t *testing.T) {
+	t.Log("Starting TestQueryOtherLedgerRetriesTransientFailure: Verifying a transient failure is retried")
+	ctx, stub, _ := newMockContext("Org1MSP")
+
+	args := [][]byte{[]byte("GetRecord"), []byte("REC001")}
+	stub.On("InvokeChaincode", "thikachain", args, "intake").Return(peer.Response{Status: 503, Message: "peer busy"}).Once()
+	stub.On("InvokeChaincode", "thikachain", args, "intake").Return(peer.Response{Status: 200, Payload: []byte(`{"id":"REC001"}`)}).Once()
+
+	contract := new(HistoryContract)
+	result, err := contract.QueryOtherLedger(ctx, "intake", "thikachain", "GetRecord", "REC001")
+
+	assert.NoError(t, err)
+	assert.Equal(t, `{"id":"REC001"}`, result)
+	stub.AssertNumberOfCalls(t, "InvokeChaincode", 2)
+	t.Log("The second attempt succeeded after a 503")
+}
+
+func TestQueryOtherLedgerGivesUp(t *testing.T) {
+	args := [][]byte{[]byte("GetRecord"), []byte("REC001")}
+	contract := new(HistoryContract)
+
+	// Transient failures stop after the attempt limit and surface the last message
+	ctx, stub, _ := newMockContext("Org1MSP")
+	stub.On("InvokeChaincode", "thikachain", args, "intake").Return(peer.Response{Status: 503, Message: "peer busy"}).Twice()
+	stub.On("InvokeChaincode", "thikachain", args, "intake").Return(peer.Response{Status: 504, Message: "timed out"})
+	_, err := contract.QueryOtherLedger(ctx, "intake", "thikachain", "GetRecord", "REC001")
+	assert.EqualError(t, err, "failed to query other ledger. Message: timed out")
+	stub.AssertNumberOfCalls(t, "InvokeChaincode", maxLedgerQueryAttempts)
+
+	// Other failures are not retried
+	ctx, stub, _ = newMockContext("Org1MSP")
+	stub.On("InvokeChaincode", "thikachain", args, "intake").Return(peer.Response{Status: 500, Message: "record REC001 does not exist"})
+	_, err = contract.QueryOtherLedger(ctx, "intake", "thikachain", "GetRecord", "REC001")
+	assert.EqualError(t, err, "failed to query other ledger. Message: record REC001 does not exist")
+	stub.AssertNumberOfCalls(t, "InvokeChaincode", 1)
+}
+
+func TestQueryMultipleLedgers(t *testing.T) {
+	t.Log("Starting TestQueryMultipleLedgers: Verifying one failing channel does not hide the others")
+	ctx, stub, _ := newMockContext("Org1MSP")
+
+	args := [][]byte{[]byte("GetRecord"), []byte("REC001")}
+	stub.On("InvokeChaincode", "thikachain", args, "intake").Return(peer.Response{Status: 200, Payload: []byte(`{"id":"REC001"}`)})
+	stub.On("InvokeChaincode", "thikachain", args, "export").Return(peer.Response{Status: 500, Message: "record REC001 does not exist"})
+
+	contract := new(HistoryContract)
+	targets := []ChannelTarget{{Channel: "intake", Chaincode: "thikachain"}, {Channel: "export", Chaincode: "thikachain"}}
+	results, err := contract.QueryMultipleLedgers(ctx, targets, "GetRecord", "REC001")
+
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"intake": `{"id":"REC001"}`,
+		"export": "error: failed to query other ledger. Message: record REC001 does not exist",
+	}, results)
+	t.Log("The intake payload and the export error were both reported")
+}
+
+// deadlineTransactionContext is a mock context that also reports a deadline
+type deadlineTransactionContext struct {
+	*MockTransactionContext
+	deadline time.Time
+}
+
+func (c *deadlineTransactionContext) Deadline() (time.Time, bool) {
+	return c.deadline, true
+}
+
+func TestQueryOtherLedgerRefusesExpiredDeadline(t *testing.T) {
+	t.Log("Starting TestQueryOtherLedgerRefusesExpiredDeadline: Verifying no invoke starts after the deadline")
+	mockCtx, stub, _ := newMockContext("Org1MSP")
+	ctx := &deadlineTransactionContext{MockTransactionContext: mockCtx, deadline: time.Now().Add(-time.Second)}
+
+	contract := new(HistoryContract)
+	_, err := contract.QueryOtherLedger(ctx, "intake", "thikachain", "GetRecord", "REC001")
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "not enough time left to query channel intake")
+	stub.AssertNotCalled(t, "InvokeChaincode", mock.Anything, mock.Anything, mock.Anything)
+	t.Log("The expired deadline stopped the query before invoking the other chaincode")
+}
+
+func TestQueryOtherLedgerWithinDeadline(t *testing.T) {
+	mockCtx, stub, _ := newMockContext("Org1MSP")
+	ctx := &deadlineTransactionContext{MockTransactionContext: mockCtx, deadline: time.Now().Add(time.Minute)}
+	stub.On("InvokeChaincode", "thikachain", [][]byte{[]byte("GetRecord"), []byte("REC001")}, "intake").Return(peer.Response{Status: 200, Payload: []byte(`{"id":"REC001"}`)})
+
+	contract := new(HistoryContract)
+	result, err := contract.QueryOtherLedger(ctx, "intake", "thikachain", "GetRecord", "REC001")
+
+	assert.NoError(t, err)
+	assert.Equal(t, `{"id":"REC001"}`, result)
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// csvImportColumns maps the header names BatchImportCSV understands to the record field they fill
+var csvImportColumns = map[string]func(record *VerificationRecord, value string){
+	"id":          func(record *VerificationRecord, value string) { record.ID = value },
+	"description": func(record *VerificationRecord, value string) { record.Description = value },
+	"status":      func(record *VerificationRecord, value string) { record.Status = value },
+}
+
+// BatchImportCSV imports records from CSV exported by upstream systems. The first row is a header naming
+// the columns (any order of id, description, status; id is required). Rows go through the same checks as
+// BatchImport in abort mode, so any problem fails the whole import.
+func (s *HistoryContract) BatchImportCSV(ctx contractapi.TransactionContextInterface, csvData string) (*BatchImportReport, error) {
+	reader := csv.NewReader(strings.NewReader(csvData))
+	// Column counts are checked below so the error can name the offending row
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, fmt.Errorf("CSV data must start with a header row")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse CSV header: %v", err)
+	}
+
+	hasID := false
+	for _, column := range header {
+		if _, known := csvImportColumns[column]; !known {
+			return nil, fmt.Errorf("unknown CSV column %q", column)
+		}
+		hasID = hasID || column == "id"
+	}
+	if !hasID {
+		return nil, fmt.Errorf("CSV header must include the id column")
+	}
+
+	var records []VerificationRecord
+	for row := 2; ; row++ {
+		fields, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse CSV row %d: %v", row, err)
+		}
+		if len(fields) != len(header) {
+			return nil, fmt.Errorf("CSV row %d has %d columns, expected %d", row, len(fields), len(header))
+		}
+
+		var record VerificationRecord
+		for i, column := range header {
+			csvImportColumns[column](&record, fields[i])
+		}
+		records = append(records, record)
+	}
+
+	return s.importRecords(ctx, records, "", BatchModeAbort, false)
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestBatchImportCSV(t *testing.T) {
+	t.Log("Starting TestBatchImportCSV: Verifying CSV rows are imported as records")
+	ctx, stub, clientIdentity := newMockContext("Org1MSP")
+	clientIdentity.On("GetAttributeValue", CreatorAttribute).Return(CreatorAttributeValue, true, nil)
+
+	written := make(map[string]VerificationRecord)
+	stub.On("GetState", mock.Anything).Return(nil, nil)
+	stub.On("PutState", mock.Anything, indexEntryValue).Return(nil)
+	stub.On("PutState", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		var record VerificationRecord
+		_ = json.Unmarshal(args.Get(1).([]byte), &record)
+		written[args.String(0)] = record
+	}).Return(nil)
+
+	contract := new(HistoryContract)
+	csvData := "id,status,description\nIMP001,CREATED,Tea consignment\nIMP002,PENDING,\"Coffee, grade AA\"\n"
+	report, err := contract.BatchImportCSV(ctx, csvData)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"IMP001", "IMP002"}, report.Imported)
+	assert.Equal(t, "Tea consignment", written["IMP001"].Description)
+	assert.Equal(t, "PENDING", written["IMP002"].Status)
+	assert.Equal(t, "Coffee, grade AA", written["IMP002"].Description)
+	t.Log("Both rows were mapped by header name and written")
+}
+
+func TestBatchImportCSVMalformedRow(t *testing.T) {
+	t.Log("Starting TestBatchImportCSVMalformedRow: Verifying a row with the wrong column count is reported by number")
+	ctx, stub, _ := newMockContext("Org1MSP")
+
+	contract := new(HistoryContract)
+	_, err := contract.BatchImportCSV(ctx, "id,description,status\nIMP001,Tea,CREATED\nIMP002,Coffee\n")
+
+	assert.EqualError(t, err, "CSV row 3 has 2 columns, expected 3")
+	stub.AssertNotCalled(t, "PutState", mock.Anything, mock.Anything)
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// tombstoneObjectType prefixes the composite keys holding deletion markers
+const tombstoneObjectType = "tombstone"
+
+// Tombstone captures who deleted a record, when and why
+type Tombstone struct {
+	ID         string `json:"id"`
+	DeletedBy  string `json:"deletedBy"`
+	DeletedAt  string `json:"deletedAt"`
+	Reason     string `json:"reason"`
+	LastStatus string `json:"lastStatus"` // Status of the record at the time it was deleted
+}
+
+// DeleteRecordWithTombstone deletes a record after writing a tombstone under a side key.
+// Fabric history only shows that a delete happened; the tombstone keeps the who and why.
+// Only the owning party or the admin MSP may delete a record.
+func (s *HistoryContract) DeleteRecordWithTombstone(ctx contractapi.TransactionContextInterface, id string, reason string) error {
+	if reason == "" {
+		return fmt.Errorf("a reason is required to delete record %s", id)
+	}
+
+	record, err := s.readRecord(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := ensureMutable(record); err != nil {
+		return err
+	}
+
+	clientIdentity, err := authorizeOwner(ctx, record, "delete")
+	if err != nil {
+		return err
+	}
+
+	deletedAt, err := txTimestampString(ctx)
+	if err != nil {
+		return err
+	}
+
+	tombstoneJSON, err := json.Marshal(Tombstone{
+		ID:         id,
+		DeletedBy:  clientIdentity,
+		DeletedAt:  deletedAt,
+		Reason:     reason,
+		LastStatus: record.Status,
+	})
+	if err != nil {
+		return err
+	}
+
+	tombstoneKey, err := ctx.GetStub().CreateCompositeKey(tombstoneObjectType, []string{id})
+	if err != nil {
+		return fmt.Errorf("failed to create tombstone key for %s: %v", id, err)
+	}
+	if err := ctx.GetStub().PutState(tombstoneKey, tombstoneJSON); err != nil {
+		return fmt.Errorf("failed to write tombstone for %s: %v", id, err)
+	}
+
+	return s.removeRecord(ctx, record)
+}
+
+// GetDeletedRecords lists the tombstones of every record deleted through DeleteRecordWithTombstone
+func (s *HistoryContract) GetDeletedRecords(ctx contractapi.TransactionContextInterface) ([]*Tombstone, error) {
+	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(tombstoneObjectType, []string{})
+	if err != nil {
+		return nil, err
+	}
+	defer resultsIterator.Close()
+
+	tombstones := []*Tombstone{}
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+
+		var tombstone Tombstone
+		if err := json.Unmarshal(queryResponse.Value, &tombstone); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal tombstone %s: %v", queryResponse.Key, err)
+		}
+		tombstones = append(tombstones, &tombstone)
+	}
+
+	return tombstones, nil
+}
+
+// SoftDeleteRecord hides a record from normal listings while keeping it in world state, unlike
+// DeleteRecord which removes the current value. Only the owning party or the admin MSP may soft delete.
+func (s *HistoryContract) SoftDeleteRecord(ctx contractapi.TransactionContextInterface, id string) error {
+	return s.setDeleted(ctx, id, true)
+}
+
+// RestoreRecord brings a soft-deleted record back into normal listings.
+// Only the owning party or the admin MSP may restore.
+func (s *HistoryContract) RestoreRecord(ctx contractapi.TransactionContextInterface, id string) error {
+	return s.setDeleted(ctx, id, false)
+}
+
+// setDeleted flips the soft-delete flag of a record after checking ownership and the current state
+func (s *HistoryContract) setDeleted(ctx contractapi.TransactionContextInterface, id string, deleted bool) error {
+	record, err := s.readRecord(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := ensureMutable(record); err != nil {
+		return err
+	}
+
+	action := "restore"
+	if deleted {
+		action = "delete"
+	}
+	if _, err := authorizeOwner(ctx, record, action); err != nil {
+		return err
+	}
+	if deleted && record.Deleted {
+		return fmt.Errorf("record %s is already deleted", id)
+	}
+	if !deleted && !record.Deleted {
+		return fmt.Errorf("record %s is not deleted", id)
+	}
+
+	timestamp, err := txTimestampString(ctx)
+	if err != nil {
+		return err
+	}
+	record.Deleted = deleted
+	record.Timestamp = timestamp
+	return s.putRecord(ctx, record)
+}
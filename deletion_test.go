@@ -0,0 +1,129 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-protos-go/ledger/queryresult"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestDeleteRecordWithTombstone(t *testing.T) {
+	t.Log("Starting TestDeleteRecordWithTombstone: Verifying a tombstone is written and listed")
+	ctx, stub, _ := newMockContext("Org2MSP")
+
+	record := VerificationRecord{ID: "REC002", Party: "Org2MSP", Status: "REJECTED"}
+	tombstoneKey := compositeKey(tombstoneObjectType, "REC002")
+	var tombstoneJSON []byte
+
+	stub.On("GetState", "REC002").Return(mustMarshal(t, record), nil)
+	stub.On("GetTxTimestamp").Return(timestamppb.New(time.Date(2024, 3, 1, 8, 0, 0, 0, time.UTC)), nil)
+	stub.On("PutState", tombstoneKey, mock.Anything).Run(func(args mock.Arguments) {
+		tombstoneJSON = args.Get(1).([]byte)
+	}).Return(nil)
+	stub.On("DelState", "REC002").Return(nil)
+	stub.On("DelState", compositeKey(partyIndex, "Org2MSP", "REC002")).Return(nil)
+	stub.On("DelState", compositeKey(statusIndex, "REJECTED", "REC002")).Return(nil)
+
+	t.Log("Deleting REC002 with a reason...")
+	contract := new(HistoryContract)
+	err := contract.DeleteRecordWithTombstone(ctx, "REC002", "duplicate entry")
+
+	assert.NoError(t, err)
+	stub.AssertExpectations(t)
+
+	var tombstone Tombstone
+	assert.NoError(t, json.Unmarshal(tombstoneJSON, &tombstone))
+	assert.Equal(t, Tombstone{ID: "REC002", DeletedBy: "Org2MSP", DeletedAt: "2024-03-01T08:00:00Z", Reason: "duplicate entry", LastStatus: "REJECTED"}, tombstone)
+
+	t.Log("Listing tombstones...")
+	stub.On("GetStateByPartialCompositeKey", tombstoneObjectType, []string{}).Return(newKVIterator(&queryresult.KV{Key: tombstoneKey, Value: tombstoneJSON}), nil)
+	tombstones, err := contract.GetDeletedRecords(ctx)
+
+	assert.NoError(t, err)
+	assert.Len(t, tombstones, 1)
+	assert.Equal(t, "duplicate entry", tombstones[0].Reason)
+	t.Log("The tombstone was listed with the deletion reason")
+}
+
+func TestDeleteRecordWithTombstoneRejectsNonOwner(t *testing.T) {
+	t.Log("Starting TestDeleteRecordWithTombstoneRejectsNonOwner: Verifying no tombstone is written for a foreign record")
+	ctx, stub, _ := newMockContext("Org3MSP")
+	stub.On("GetState", "REC002").Return(mustMarshal(t, VerificationRecord{ID: "REC002", Party: "Org2MSP", Status: "REJECTED"}), nil)
+
+	contract := new(HistoryContract)
+	err := contract.DeleteRecordWithTombstone(ctx, "REC002", "duplicate entry")
+
+	assert.ErrorIs(t, err, ErrUnauthorized)
+	stub.AssertNotCalled(t, "PutState", mock.Anything, mock.Anything)
+	stub.AssertNotCalled(t, "DelState", mock.Anything)
+}
+
+func TestDeleteRecordWithTombstoneRequiresReason(t *testing.T) {
+	t.Log("Starting TestDeleteRecordWithTombstoneRequiresReason: Verifying an empty reason is refused")
+	ctx, stub, _ := newMockContext("Org2MSP")
+
+	contract := new(HistoryContract)
+	err := contract.DeleteRecordWithTombstone(ctx, "REC002", "")
+
+	assert.EqualError(t, err, "a reason is required to delete record REC002")
+	stub.AssertNotCalled(t, "DelState", mock.Anything)
+}
+
+func TestSoftDeleteAndRestoreRecord(t *testing.T) {
+	t.Log("Starting TestSoftDeleteAndRestoreRecord: Verifying soft-deleted records leave listings until restored")
+	contract := new(HistoryContract)
+	active := VerificationRecord{ID: "REC001", Party: "Org2MSP", Status: "PENDING"}
+
+	t.Log("Soft deleting REC001...")
+	ctx, stub, _ := newMockContext("Org2MSP")
+	stub.On("GetState", "REC001").Return(mustMarshal(t, active), nil)
+	stub.On("GetTxTimestamp").Return(timestamppb.Now(), nil)
+	var deleted VerificationRecord
+	stub.On("PutState", "REC001", mock.Anything).Run(func(args mock.Arguments) {
+		_ = json.Unmarshal(args.Get(1).([]byte), &deleted)
+	}).Return(nil)
+
+	assert.NoError(t, contract.SoftDeleteRecord(ctx, "REC001"))
+	assert.True(t, deleted.Deleted)
+	stub.AssertNotCalled(t, "DelState", mock.Anything)
+
+	t.Log("Listing records with and without soft-deleted entries...")
+	listCtx, listStub, _ := newMockContext("Org2MSP")
+	other := VerificationRecord{ID: "REC002", Party: "Org2MSP", Status: "CREATED"}
+	listStub.On("GetStateByRange", "", "").Return(newStateIterator(t, deleted, other), nil).Once()
+	listed, err := contract.GetAllRecords(listCtx, false)
+	assert.NoError(t, err)
+	assert.Equal(t, []*VerificationRecord{&other}, listed)
+
+	listStub.On("GetStateByRange", "", "").Return(newStateIterator(t, deleted, other), nil).Once()
+	listed, err = contract.GetAllRecords(listCtx, true)
+	assert.NoError(t, err)
+	assert.Len(t, listed, 2)
+
+	t.Log("Restoring REC001...")
+	restoreCtx, restoreStub, _ := newMockContext("Org2MSP")
+	restoreStub.On("GetState", "REC001").Return(mustMarshal(t, deleted), nil)
+	restoreStub.On("GetTxTimestamp").Return(timestamppb.Now(), nil)
+	var restored VerificationRecord
+	restoreStub.On("PutState", "REC001", mock.Anything).Run(func(args mock.Arguments) {
+		_ = json.Unmarshal(args.Get(1).([]byte), &restored)
+	}).Return(nil)
+
+	assert.NoError(t, contract.RestoreRecord(restoreCtx, "REC001"))
+	assert.False(t, restored.Deleted)
+	t.Log("The record was hidden while soft-deleted and visible again after restore")
+}
+
+func TestRestoreRecordNotDeleted(t *testing.T) {
+	ctx, stub, _ := newMockContext("Org2MSP")
+	stub.On("GetState", "REC001").Return(mustMarshal(t, VerificationRecord{ID: "REC001", Party: "Org2MSP"}), nil)
+
+	contract := new(HistoryContract)
+	err := contract.RestoreRecord(ctx, "REC001")
+
+	assert.EqualError(t, err, "record REC001 is not deleted")
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// probeObjectType prefixes the scratch keys used by diagnostic functions
+const probeObjectType = "probe"
+
+// VerifyWriteVisibility is a debugging aid that writes a probe value under a side key derived from id,
+// reads it back within the same transaction and reports whether the read matched the write.
+// The probe is deleted again before returning so no scratch data is committed.
+//
+// Note: a stock Fabric peer serves GetState from committed state only, so false is the expected
+// answer there; true indicates the peer (or a test harness) exposes read-your-writes semantics.
+func (s *HistoryContract) VerifyWriteVisibility(ctx contractapi.TransactionContextInterface, id string) (bool, error) {
+	probeKey, err := ctx.GetStub().CreateCompositeKey(probeObjectType, []string{id})
+	if err != nil {
+		return false, fmt.Errorf("failed to create probe key for %s: %v", id, err)
+	}
+
+	timestamp, err := txTimestampString(ctx)
+	if err != nil {
+		return false, err
+	}
+	probeValue := []byte("probe:" + id + ":" + timestamp)
+
+	if err := ctx.GetStub().PutState(probeKey, probeValue); err != nil {
+		return false, fmt.Errorf("failed to write probe for %s: %v", id, err)
+	}
+
+	readBack, err := ctx.GetStub().GetState(probeKey)
+	if err != nil {
+		return false, fmt.Errorf("failed to read probe for %s: %v", id, err)
+	}
+
+	if err := ctx.GetStub().DelState(probeKey); err != nil {
+		return false, fmt.Errorf("failed to clean up probe for %s: %v", id, err)
+	}
+
+	return bytes.Equal(readBack, probeValue), nil
+}
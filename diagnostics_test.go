@@ -0,0 +1,34 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestVerifyWriteVisibility(t *testing.T) {
+	t.Log("Starting TestVerifyWriteVisibility: Verifying the probe value is read back and cleaned up")
+	ctx, stub, _ := newMockContext("Org1MSP")
+
+	probeKey := compositeKey(probeObjectType, "diag")
+	var written []byte
+	stub.On("GetTxTimestamp").Return(timestamppb.New(time.Date(2024, 3, 1, 8, 0, 0, 0, time.UTC)), nil)
+	stub.On("PutState", probeKey, mock.Anything).Run(func(args mock.Arguments) {
+		written = args.Get(1).([]byte)
+	}).Return(nil)
+	// Simulate a write cache that serves the pending write
+	stub.On("GetState", probeKey).Return([]byte("probe:diag:2024-03-01T08:00:00Z"), nil)
+	stub.On("DelState", probeKey).Return(nil)
+
+	contract := new(HistoryContract)
+	visible, err := contract.VerifyWriteVisibility(ctx, "diag")
+
+	assert.NoError(t, err)
+	assert.True(t, visible)
+	assert.Equal(t, "probe:diag:2024-03-01T08:00:00Z", string(written))
+	stub.AssertExpectations(t)
+	t.Log("The written value was read back and the probe key was deleted")
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// AttachDocumentHash records the SHA-256 of an external document on a record. The document itself
+// stays off-chain; holders can later prove they have the same file with VerifyDocumentHash.
+func (s *HistoryContract) AttachDocumentHash(ctx contractapi.TransactionContextInterface, id string, hexHash string) error {
+	if !isSHA256Hex(hexHash) {
+		return fmt.Errorf("document hash %q is not a 64 character hex SHA-256 digest", hexHash)
+	}
+
+	record, err := s.readRecord(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := ensureMutable(record); err != nil {
+		return err
+	}
+
+	if _, err := authorizeOwner(ctx, record, "attach a document to"); err != nil {
+		return err
+	}
+
+	timestamp, err := txTimestampString(ctx)
+	if err != nil {
+		return err
+	}
+	record.DocumentHash = strings.ToLower(hexHash)
+	record.Timestamp = timestamp
+	return s.putRecord(ctx, record)
+}
+
+// VerifyDocumentHash reports whether a presented document hash matches the one attached to a record.
+// Hex case is ignored so clients don't have to normalize their digests.
+func (s *HistoryContract) VerifyDocumentHash(ctx contractapi.TransactionContextInterface, id string, hexHash string) (bool, error) {
+	if !isSHA256Hex(hexHash) {
+		return false, fmt.Errorf("document hash %q is not a 64 character hex SHA-256 digest", hexHash)
+	}
+
+	record, err := s.readRecord(ctx, id)
+	if err != nil {
+		return false, err
+	}
+	if record.DocumentHash == "" {
+		return false, fmt.Errorf("record %s has no document hash", id)
+	}
+
+	return strings.EqualFold(record.DocumentHash, hexHash), nil
+}
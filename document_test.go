@@ -0,0 +1,67 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestAttachDocumentHash(t *testing.T) {
+	t.Log("Starting TestAttachDocumentHash: Verifying a valid document hash is stored on the record")
+	ctx, stub, _ := newMockContext("Org2MSP")
+
+	stub.On("GetState", "REC001").Return(mustMarshal(t, VerificationRecord{ID: "REC001", Party: "Org2MSP", Status: "PENDING", Version: 1}), nil)
+	stub.On("GetTxTimestamp").Return(timestamppb.Now(), nil)
+	var written VerificationRecord
+	stub.On("PutState", "REC001", mock.Anything).Run(func(args mock.Arguments) {
+		_ = json.Unmarshal(args.Get(1).([]byte), &written)
+	}).Return(nil)
+
+	sum := sha256.Sum256([]byte("shipping manifest"))
+	contract := new(HistoryContract)
+	err := contract.AttachDocumentHash(ctx, "REC001", hex.EncodeToString(sum[:]))
+
+	assert.NoError(t, err)
+	assert.Equal(t, hex.EncodeToString(sum[:]), written.DocumentHash)
+}
+
+func TestAttachDocumentHashInvalidFormat(t *testing.T) {
+	t.Log("Starting TestAttachDocumentHashInvalidFormat: Verifying malformed hashes are rejected")
+	contract := new(HistoryContract)
+
+	for _, hash := range []string{"", "abc123", "zz" + strings.Repeat("a", 62)} {
+		ctx, stub, _ := newMockContext("Org2MSP")
+
+		err := contract.AttachDocumentHash(ctx, "REC001", hash)
+
+		assert.Error(t, err, hash)
+		assert.Contains(t, err.Error(), "is not a 64 character hex SHA-256 digest")
+		stub.AssertNotCalled(t, "PutState", mock.Anything, mock.Anything)
+	}
+}
+
+func TestVerifyDocumentHash(t *testing.T) {
+	t.Log("Starting TestVerifyDocumentHash: Verifying presented hashes are compared to the stored one")
+	ctx, stub, _ := newMockContext("Org3MSP")
+
+	sum := sha256.Sum256([]byte("shipping manifest"))
+	stored := hex.EncodeToString(sum[:])
+	stub.On("GetState", "REC001").Return(mustMarshal(t, VerificationRecord{ID: "REC001", Party: "Org2MSP", DocumentHash: stored}), nil)
+
+	contract := new(HistoryContract)
+	match, err := contract.VerifyDocumentHash(ctx, "REC001", stored)
+	assert.NoError(t, err)
+	assert.True(t, match)
+
+	other := sha256.Sum256([]byte("tampered manifest"))
+	match, err = contract.VerifyDocumentHash(ctx, "REC001", hex.EncodeToString(other[:]))
+	assert.NoError(t, err)
+	assert.False(t, match)
+	t.Log("The original document matched and a different one did not")
+}
@@ -0,0 +1,12 @@
+package main
+
+import "errors"
+
+// Sentinel errors wrapped by the contract so clients can classify failures with errors.Is
+// instead of matching on message text.
+var (
+	ErrRecordNotFound = errors.New("record not found")
+	ErrRecordExists   = errors.New("record already exists")
+	ErrInvalidStatus  = errors.New("invalid status")
+	ErrUnauthorized   = errors.New("unauthorized")
+)
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestSentinelErrors(t *testing.T) {
+	t.Log("Starting TestSentinelErrors: Verifying failures wrap the sentinel clients classify them by")
+	contract := new(HistoryContract)
+	existing := VerificationRecord{ID: "REC001", Description: "Manifest", Party: "Org2MSP", Status: "CREATED", Version: 1}
+
+	notFoundCtx, notFoundStub, _ := newMockContext("Org2MSP")
+	notFoundStub.On("GetState", "REC404").Return(nil, nil)
+	_, err := contract.GetRecord(notFoundCtx, "REC404")
+	assert.True(t, errors.Is(err, ErrRecordNotFound), err)
+
+	existsCtx, existsStub, clientIdentity := newMockContext("Org2MSP")
+	existsStub.On("GetState", compositeKey(configObjectType, configWorkflowGraph)).Return(nil, nil)
+	existsStub.On("GetState", "REC001").Return(mustMarshal(t, existing), nil)
+	clientIdentity.On("GetAttributeValue", CreatorAttribute).Return(CreatorAttributeValue, true, nil)
+	err = contract.CreateRecord(existsCtx, "REC001", "Manifest", "CREATED", "", 0, "")
+	assert.True(t, errors.Is(err, ErrRecordExists), err)
+
+	statusCtx, statusStub, _ := newMockContext("Org2MSP")
+	statusStub.On("GetState", compositeKey(configObjectType, configWorkflowGraph)).Return(nil, nil)
+	statusStub.On("GetState", "REC001").Return(mustMarshal(t, existing), nil)
+	statusStub.On("GetTxTimestamp").Return(timestamppb.Now(), nil)
+	err = contract.UpdateRecord(statusCtx, "REC001", "Manifest", "VERIFIED", "", 1)
+	assert.True(t, errors.Is(err, ErrInvalidStatus), err)
+	_, err = contract.QueryRecordsByStatus(statusCtx, "VERIFED")
+	assert.True(t, errors.Is(err, ErrInvalidStatus), err)
+
+	unauthorizedCtx, unauthorizedStub, _ := newMockContext("Org3MSP")
+	unauthorizedStub.On("GetState", compositeKey(configObjectType, configWorkflowGraph)).Return(nil, nil)
+	unauthorizedStub.On("GetState", "REC001").Return(mustMarshal(t, existing), nil)
+	err = contract.UpdateRecord(unauthorizedCtx, "REC001", "Manifest", "PENDING", "", 1)
+	assert.True(t, errors.Is(err, ErrUnauthorized), err)
+	err = contract.SetDefaultPageSize(unauthorizedCtx, 50)
+	assert.True(t, errors.Is(err, ErrUnauthorized), err)
+
+	for _, stub := range []*MockChaincodeStub{notFoundStub, existsStub, statusStub, unauthorizedStub} {
+		stub.AssertNotCalled(t, "PutState", mock.Anything, mock.Anything)
+	}
+	t.Log("Each failure path wrapped its sentinel")
+}
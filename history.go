@@ -0,0 +1,412 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// HistoryFieldsEntry is a history entry trimmed down to a requested subset of record fields
+type HistoryFieldsEntry struct {
+	TxId      string                 `json:"txId"`
+	Timestamp time.Time              `json:"timestamp"`
+	IsDelete  bool                   `json:"isDelete"`
+	Fields    map[string]interface{} `json:"fields"`
+}
+
+// RecordWithHistory bundles a record's current state with its full chain of custody
+type RecordWithHistory struct {
+	Record  *VerificationRecord  `json:"record"`
+	History []HistoryQueryResult `json:"history"`
+}
+
+// getChronologicalHistory returns a record's unmasked history ordered from oldest to newest.
+// Fabric does not guarantee an ordering that suits every analysis, so entries are sorted by timestamp.
+// Callers must not hand the records back to clients without redacting them.
+func (s *HistoryContract) getChronologicalHistory(ctx contractapi.TransactionContextInterface, id string) ([]HistoryQueryResult, error) {
+	history, err := s.readRecordHistory(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(history, func(i, j int) bool {
+		return history[i].Timestamp.Before(history[j].Timestamp)
+	})
+
+	return history, nil
+}
+
+// GetRecordHistoryBetween returns the history entries whose ledger timestamp falls within the inclusive
+// RFC3339 range [start, end]. An empty start means from the beginning; an empty end means until the
+// current transaction's timestamp, which keeps the result deterministic across endorsing peers.
+func (s *HistoryContract) GetRecordHistoryBetween(ctx contractapi.TransactionContextInterface, id string, start string, end string) ([]HistoryQueryResult, error) {
+	var from time.Time
+	if start != "" {
+		parsed, err := time.Parse(time.RFC3339, start)
+		if err != nil {
+			return nil, fmt.Errorf("invalid start time %q: %v", start, err)
+		}
+		from = parsed
+	}
+
+	var until time.Time
+	if end != "" {
+		parsed, err := time.Parse(time.RFC3339, end)
+		if err != nil {
+			return nil, fmt.Errorf("invalid end time %q: %v", end, err)
+		}
+		until = parsed
+	} else {
+		txTimestamp, err := ctx.GetStub().GetTxTimestamp()
+		if err != nil {
+			return nil, err
+		}
+		until = txTimestamp.AsTime()
+	}
+
+	if from.After(until) {
+		return nil, fmt.Errorf("start time %s is after end time %s", from.Format(time.RFC3339), until.Format(time.RFC3339))
+	}
+
+	history, err := s.GetRecordHistory(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	inRange := []HistoryQueryResult{}
+	for _, entry := range history {
+		if entry.Timestamp.Before(from) || entry.Timestamp.After(until) {
+			continue
+		}
+		inRange = append(inRange, entry)
+	}
+
+	return inRange, nil
+}
+
+// GetRecordHistoryFields returns a record's history with each version reduced to the fields named in
+// fieldsJSON, a JSON array of record JSON names such as ["status","party"]. This keeps focused audit views small.
+// Delete markers are kept with empty fields; fields omitted from a version (e.g. unset metadata) are left out.
+// Each version is masked as in GetRecord before its fields are projected.
+func (s *HistoryContract) GetRecordHistoryFields(ctx contractapi.TransactionContextInterface, id string, fieldsJSON string) ([]HistoryFieldsEntry, error) {
+	var fields []string
+	if err := json.Unmarshal([]byte(fieldsJSON), &fields); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal field list: %v", err)
+	}
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("at least one field must be requested")
+	}
+
+	known := recordFieldNames()
+	for _, field := range fields {
+		if !known[field] {
+			return nil, fmt.Errorf("unknown record field %q", field)
+		}
+	}
+
+	history, err := s.GetRecordHistory(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := []HistoryFieldsEntry{}
+	for _, entry := range history {
+		trimmed := HistoryFieldsEntry{
+			TxId:      entry.TxId,
+			Timestamp: entry.Timestamp,
+			IsDelete:  entry.IsDelete,
+			Fields:    make(map[string]interface{}),
+		}
+
+		if entry.Record != nil {
+			all, err := recordFieldValues(entry.Record)
+			if err != nil {
+				return nil, err
+			}
+			for _, field := range fields {
+				if value, ok := all[field]; ok {
+					trimmed.Fields[field] = value
+				}
+			}
+		}
+
+		entries = append(entries, trimmed)
+	}
+
+	return entries, nil
+}
+
+// GetRecordDiff compares the versions of a record written by transactions txId1 and txId2 and returns
+// every differing field as "old -> new", e.g. {"status":"PENDING -> VERIFIED"}. The derived hash field is
+// not reported since it changes with any other field. Fields absent from one version show as empty.
+// Both versions are masked as in GetRecord before they are compared, so a hidden description never leaks.
+func (s *HistoryContract) GetRecordDiff(ctx contractapi.TransactionContextInterface, id string, txId1 string, txId2 string) (map[string]string, error) {
+	history, err := s.GetRecordHistory(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	versions := make(map[string]*VerificationRecord)
+	for _, entry := range history {
+		if entry.TxId != txId1 && entry.TxId != txId2 {
+			continue
+		}
+		if entry.Record == nil {
+			return nil, fmt.Errorf("transaction %s deleted record %s and has no version to compare", entry.TxId, id)
+		}
+		versions[entry.TxId] = entry.Record
+	}
+	for _, txID := range []string{txId1, txId2} {
+		if versions[txID] == nil {
+			return nil, fmt.Errorf("transaction %s not found in the history of record %s", txID, id)
+		}
+	}
+
+	before, err := recordFieldValues(versions[txId1])
+	if err != nil {
+		return nil, err
+	}
+	after, err := recordFieldValues(versions[txId2])
+	if err != nil {
+		return nil, err
+	}
+
+	diff := make(map[string]string)
+	for field := range recordFieldNames() {
+		if field == "hash" {
+			continue
+		}
+		oldValue, newValue := formatFieldValue(before[field]), formatFieldValue(after[field])
+		if oldValue != newValue {
+			diff[field] = oldValue + " -> " + newValue
+		}
+	}
+	return diff, nil
+}
+
+// GetRecordHistoryExcludingDeletes returns a record's history without the delete markers, for clean
+// audit timelines. GetRecordHistory still returns every entry for forensic use.
+func (s *HistoryContract) GetRecordHistoryExcludingDeletes(ctx contractapi.TransactionContextInterface, id string) ([]HistoryQueryResult, error) {
+	history, err := s.GetRecordHistory(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	var filtered []HistoryQueryResult
+	for _, entry := range history {
+		if entry.IsDelete {
+			continue
+		}
+		filtered = append(filtered, entry)
+	}
+	return filtered, nil
+}
+
+// historyCSVHeader names the columns written by ExportHistoryCSV
+var historyCSVHeader = []string{"TxId", "Timestamp", "IsDelete", "Status", "Party", "Description"}
+
+// ExportHistoryCSV renders a record's full history as CSV for auditors, one row per entry in the order
+// Fabric returns them. Delete markers leave the record columns blank. Confidential descriptions are masked
+// as in GetRecord.
+func (s *HistoryContract) ExportHistoryCSV(ctx contractapi.TransactionContextInterface, id string) (string, error) {
+	history, err := s.GetRecordHistory(ctx, id)
+	if err != nil {
+		return "", err
+	}
+
+	var out strings.Builder
+	writer := csv.NewWriter(&out)
+	if err := writer.Write(historyCSVHeader); err != nil {
+		return "", err
+	}
+	for _, entry := range history {
+		row := []string{entry.TxId, entry.Timestamp.UTC().Format(time.RFC3339), strconv.FormatBool(entry.IsDelete), "", "", ""}
+		if !entry.IsDelete && entry.Record != nil {
+			row[3], row[4], row[5] = entry.Record.Status, entry.Record.Party, entry.Record.Description
+		}
+		if err := writer.Write(row); err != nil {
+			return "", err
+		}
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return "", fmt.Errorf("failed to write CSV for record %s: %v", id, err)
+	}
+	return out.String(), nil
+}
+
+// GetRecordHistoryPaginated returns up to limit history entries starting at offset, in the order Fabric
+// returns them. The history iterator has no native offset, so earlier entries are skipped without being
+// unmarshalled and iteration stops once the window is full.
+// Confidential descriptions are masked as in GetRecord.
+func (s *HistoryContract) GetRecordHistoryPaginated(ctx contractapi.TransactionContextInterface, id string, offset int, limit int) ([]HistoryQueryResult, error) {
+	if offset < 0 {
+		return nil, fmt.Errorf("offset must not be negative, got %d", offset)
+	}
+	if limit <= 0 || limit > int(maxPageSize) {
+		return nil, fmt.Errorf("limit must be between 1 and %d, got %d", maxPageSize, limit)
+	}
+
+	resultsIterator, err := ctx.GetStub().GetHistoryForKey(id)
+	if err != nil {
+		return nil, err
+	}
+	defer resultsIterator.Close()
+
+	page := []HistoryQueryResult{}
+	for position := 0; resultsIterator.HasNext() && len(page) < limit; position++ {
+		response, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+		if position < offset {
+			continue
+		}
+
+		var record *VerificationRecord
+		if !response.IsDelete {
+			if err := json.Unmarshal(response.Value, &record); err != nil {
+				return nil, err
+			}
+		}
+		page = append(page, HistoryQueryResult{
+			TxId:      response.TxId,
+			Timestamp: response.Timestamp.AsTime(),
+			IsDelete:  response.IsDelete,
+			Record:    record,
+		})
+	}
+
+	if err := redactHistory(ctx, page); err != nil {
+		return nil, err
+	}
+	return page, nil
+}
+
+// GetRecordVersionCount counts the entries in a record's history, leaving out delete markers when
+// excludeDeletes is set. Values are never unmarshalled, so even a long history is cheap to count.
+// A record that never existed has no history and fails with an error.
+func (s *HistoryContract) GetRecordVersionCount(ctx contractapi.TransactionContextInterface, id string, excludeDeletes bool) (int, error) {
+	resultsIterator, err := ctx.GetStub().GetHistoryForKey(id)
+	if err != nil {
+		return 0, err
+	}
+	defer resultsIterator.Close()
+
+	entries, count := 0, 0
+	for resultsIterator.HasNext() {
+		response, err := resultsIterator.Next()
+		if err != nil {
+			return 0, err
+		}
+		entries++
+		if excludeDeletes && response.IsDelete {
+			continue
+		}
+		count++
+	}
+
+	if entries == 0 {
+		return 0, fmt.Errorf("record %s does not exist: %w", id, ErrRecordNotFound)
+	}
+	return count, nil
+}
+
+// GetRecordWithHistory returns the current record and its history in one call, for detail views.
+// The current state is read first so a missing record fails before the history is scanned.
+// Confidential descriptions are masked in the record and in every history version alike.
+func (s *HistoryContract) GetRecordWithHistory(ctx contractapi.TransactionContextInterface, id string) (*RecordWithHistory, error) {
+	record, err := s.GetRecord(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	history, err := s.GetRecordHistory(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &RecordWithHistory{Record: record, History: history}, nil
+}
+
+// GetRecordAtTx returns a record as it was written by one transaction, stopping the history scan
+// as soon as the transaction is found. Confidential descriptions are masked as in GetRecord.
+func (s *HistoryContract) GetRecordAtTx(ctx contractapi.TransactionContextInterface, id string, txId string) (*VerificationRecord, error) {
+	resultsIterator, err := ctx.GetStub().GetHistoryForKey(id)
+	if err != nil {
+		return nil, err
+	}
+	defer resultsIterator.Close()
+
+	for resultsIterator.HasNext() {
+		response, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+		if response.TxId != txId {
+			continue
+		}
+		if response.IsDelete {
+			return nil, fmt.Errorf("transaction %s deleted record %s", txId, id)
+		}
+
+		var record VerificationRecord
+		if err := json.Unmarshal(response.Value, &record); err != nil {
+			return nil, err
+		}
+		if err := redactConfidential(ctx, &record); err != nil {
+			return nil, err
+		}
+		return &record, nil
+	}
+
+	return nil, fmt.Errorf("transaction %s not found in the history of record %s", txId, id)
+}
+
+// recordFieldValues flattens a record into its JSON fields.
+// Going through JSON keeps field names and encodings identical to what clients already see.
+func recordFieldValues(record *VerificationRecord) (map[string]interface{}, error) {
+	recordJSON, err := json.Marshal(record)
+	if err != nil {
+		return nil, err
+	}
+	var values map[string]interface{}
+	if err := json.Unmarshal(recordJSON, &values); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+// formatFieldValue renders a decoded JSON value for display; strings are shown bare, anything else as JSON
+func formatFieldValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	default:
+		encoded, _ := json.Marshal(v)
+		return string(encoded)
+	}
+}
+
+// recordFieldNames returns the JSON names of every VerificationRecord field
+func recordFieldNames() map[string]bool {
+	names := make(map[string]bool)
+	recordType := reflect.TypeOf(VerificationRecord{})
+	for i := 0; i < recordType.NumField(); i++ {
+		name, _, _ := strings.Cut(recordType.Field(i).Tag.Get("json"), ",")
+		if name != "" && name != "-" {
+			names[name] = true
+		}
+	}
+	return names
+}
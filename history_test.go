@@ -0,0 +1,377 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-protos-go/ledger/queryresult"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetRecordHistoryBetween(t *testing.T) {
+	t.Log("Starting TestGetRecordHistoryBetween: Verifying only in-range history entries are returned")
+	ctx, stub, _ := newMockContext("Org1MSP")
+
+	base := time.Date(2024, 3, 1, 8, 0, 0, 0, time.UTC)
+	stub.On("GetHistoryForKey", "REC001").Return(newHistoryIterator(
+		historyEntry(t, "tx1", base, &VerificationRecord{ID: "REC001", Status: "CREATED"}),
+		historyEntry(t, "tx2", base.Add(24*time.Hour), &VerificationRecord{ID: "REC001", Status: "PENDING"}),
+		historyEntry(t, "tx3", base.Add(48*time.Hour), &VerificationRecord{ID: "REC001", Status: "VERIFIED"}),
+	), nil)
+
+	contract := new(HistoryContract)
+	history, err := contract.GetRecordHistoryBetween(ctx, "REC001", "2024-03-02T08:00:00Z", "2024-03-03T08:00:00Z")
+
+	assert.NoError(t, err)
+	var txIDs []string
+	for _, entry := range history {
+		txIDs = append(txIDs, entry.TxId)
+	}
+	assert.Equal(t, []string{"tx2", "tx3"}, txIDs)
+	t.Log("The entry before the range was excluded; both inclusive bounds were kept")
+}
+
+func TestGetRecordHistoryBetweenInvertedRange(t *testing.T) {
+	t.Log("Starting TestGetRecordHistoryBetweenInvertedRange: Verifying a start after the end is rejected")
+	ctx, _, _ := newMockContext("Org1MSP")
+
+	contract := new(HistoryContract)
+	_, err := contract.GetRecordHistoryBetween(ctx, "REC001", "2024-03-03T00:00:00Z", "2024-03-01T00:00:00Z")
+
+	assert.EqualError(t, err, "start time 2024-03-03T00:00:00Z is after end time 2024-03-01T00:00:00Z")
+}
+
+func TestGetRecordHistoryFieldsStatusOnly(t *testing.T) {
+	t.Log("Starting TestGetRecordHistoryFieldsStatusOnly: Verifying history can be reduced to the status field")
+	ctx, stub, _ := newMockContext("Org1MSP")
+
+	base := time.Date(2024, 3, 1, 8, 0, 0, 0, time.UTC)
+	stub.On("GetHistoryForKey", "REC001").Return(newHistoryIterator(
+		historyEntry(t, "tx1", base, &VerificationRecord{ID: "REC001", Party: "Org1MSP", Status: "CREATED"}),
+		historyEntry(t, "tx2", base.Add(time.Hour), &VerificationRecord{ID: "REC001", Party: "Org1MSP", Status: "PENDING"}),
+		historyEntry(t, "tx3", base.Add(2*time.Hour), &VerificationRecord{ID: "REC001", Party: "Org2MSP", Status: "VERIFIED"}),
+	), nil)
+
+	contract := new(HistoryContract)
+	entries, err := contract.GetRecordHistoryFields(ctx, "REC001", `["status"]`)
+
+	assert.NoError(t, err)
+	assert.Len(t, entries, 3)
+	for i, status := range []string{"CREATED", "PENDING", "VERIFIED"} {
+		assert.Equal(t, map[string]interface{}{"status": status}, entries[i].Fields)
+	}
+	t.Log("Each version carried only its status")
+}
+
+func TestGetRecordHistoryFieldsMasksDescription(t *testing.T) {
+	t.Log("Starting TestGetRecordHistoryFieldsMasksDescription: Verifying a requested confidential description is masked")
+	ctx, stub, _ := newMockContext("Org3MSP")
+
+	stub.On("GetHistoryForKey", "REC001").Return(newHistoryIterator(
+		historyEntry(t, "tx1", time.Date(2024, 3, 1, 8, 0, 0, 0, time.UTC), &VerificationRecord{ID: "REC001", Description: "secret", Party: "Org2MSP", Status: "CREATED", Confidential: true}),
+	), nil)
+
+	contract := new(HistoryContract)
+	entries, err := contract.GetRecordHistoryFields(ctx, "REC001", `["description"]`)
+
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"description": redactedValue}, entries[0].Fields)
+}
+
+func TestGetRecordHistoryFieldsUnknownField(t *testing.T) {
+	t.Log("Starting TestGetRecordHistoryFieldsUnknownField: Verifying unknown field names are rejected")
+	ctx, _, _ := newMockContext("Org1MSP")
+
+	contract := new(HistoryContract)
+	_, err := contract.GetRecordHistoryFields(ctx, "REC001", `["status","owner"]`)
+
+	assert.EqualError(t, err, `unknown record field "owner"`)
+}
+
+func TestGetRecordDiffStatusOnly(t *testing.T) {
+	t.Log("Starting TestGetRecordDiffStatusOnly: Verifying only the changed field is reported")
+	ctx, stub, _ := newMockContext("Org1MSP")
+
+	base := time.Date(2024, 3, 1, 8, 0, 0, 0, time.UTC)
+	stub.On("GetHistoryForKey", "REC001").Return(newHistoryIterator(
+		historyEntry(t, "tx1", base, &VerificationRecord{ID: "REC001", Description: "Manifest", Party: "Org1MSP", Status: "PENDING"}),
+		historyEntry(t, "tx2", base.Add(time.Hour), &VerificationRecord{ID: "REC001", Description: "Manifest", Party: "Org1MSP", Status: "VERIFIED"}),
+	), nil)
+
+	contract := new(HistoryContract)
+	diff, err := contract.GetRecordDiff(ctx, "REC001", "tx1", "tx2")
+
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"status": "PENDING -> VERIFIED"}, diff)
+	t.Log("The status change was the only difference")
+}
+
+func TestGetRecordDiffMasksConfidentialDescription(t *testing.T) {
+	t.Log("Starting TestGetRecordDiffMasksConfidentialDescription: Verifying a hidden description is not diffed")
+	ctx, stub, _ := newMockContext("Org3MSP")
+
+	base := time.Date(2024, 3, 1, 8, 0, 0, 0, time.UTC)
+	stub.On("GetHistoryForKey", "REC001").Return(newHistoryIterator(
+		historyEntry(t, "tx1", base, &VerificationRecord{ID: "REC001", Description: "old secret", Party: "Org2MSP", Status: "PENDING", Confidential: true}),
+		historyEntry(t, "tx2", base.Add(time.Hour), &VerificationRecord{ID: "REC001", Description: "new secret", Party: "Org2MSP", Status: "VERIFIED", Confidential: true}),
+	), nil)
+
+	contract := new(HistoryContract)
+	diff, err := contract.GetRecordDiff(ctx, "REC001", "tx1", "tx2")
+
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"status": "PENDING -> VERIFIED"}, diff)
+	t.Log("Only the status change was reported to the non-owner")
+}
+
+func TestGetRecordDiffUnknownTx(t *testing.T) {
+	ctx, stub, _ := newMockContext("Org1MSP")
+	stub.On("GetHistoryForKey", "REC001").Return(newHistoryIterator(
+		historyEntry(t, "tx1", time.Now(), &VerificationRecord{ID: "REC001", Status: "PENDING"}),
+	), nil)
+
+	contract := new(HistoryContract)
+	_, err := contract.GetRecordDiff(ctx, "REC001", "tx1", "tx9")
+
+	assert.EqualError(t, err, "transaction tx9 not found in the history of record REC001")
+}
+
+func TestGetRecordAtTx(t *testing.T) {
+	t.Log("Starting TestGetRecordAtTx: Verifying the version written by a given transaction is returned")
+	ctx, stub, _ := newMockContext("Org1MSP")
+
+	base := time.Date(2024, 3, 1, 8, 0, 0, 0, time.UTC)
+	stub.On("GetHistoryForKey", "REC001").Return(newHistoryIterator(
+		historyEntry(t, "tx3", base.Add(2*time.Hour), &VerificationRecord{ID: "REC001", Description: "Manifest", Party: "Org2MSP", Status: "VERIFIED", Version: 3}),
+		historyEntry(t, "tx2", base.Add(time.Hour), &VerificationRecord{ID: "REC001", Description: "Manifest", Party: "Org2MSP", Status: "PENDING", Version: 2}),
+		historyEntry(t, "tx1", base, &VerificationRecord{ID: "REC001", Description: "Draft", Party: "Org1MSP", Status: "CREATED", Version: 1}),
+	), nil)
+
+	contract := new(HistoryContract)
+	record, err := contract.GetRecordAtTx(ctx, "REC001", "tx2")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "PENDING", record.Status)
+	assert.Equal(t, "Org2MSP", record.Party)
+	assert.Equal(t, 2, record.Version)
+	t.Log("The middle version was returned for tx2")
+}
+
+func TestGetRecordAtTxDeleteOrMissing(t *testing.T) {
+	contract := new(HistoryContract)
+
+	ctx, stub, _ := newMockContext("Org1MSP")
+	stub.On("GetHistoryForKey", "REC001").Return(newHistoryIterator(
+		historyEntry(t, "tx2", time.Now(), nil),
+		historyEntry(t, "tx1", time.Now(), &VerificationRecord{ID: "REC001", Status: "CREATED"}),
+	), nil)
+	_, err := contract.GetRecordAtTx(ctx, "REC001", "tx2")
+	assert.EqualError(t, err, "transaction tx2 deleted record REC001")
+
+	ctx, stub, _ = newMockContext("Org1MSP")
+	stub.On("GetHistoryForKey", "REC001").Return(newHistoryIterator(
+		historyEntry(t, "tx1", time.Now(), &VerificationRecord{ID: "REC001", Status: "CREATED"}),
+	), nil)
+	_, err = contract.GetRecordAtTx(ctx, "REC001", "tx9")
+	assert.EqualError(t, err, "transaction tx9 not found in the history of record REC001")
+}
+
+func TestGetRecordWithHistory(t *testing.T) {
+	t.Log("Starting TestGetRecordWithHistory: Verifying the current state and history are returned together")
+	ctx, stub, _ := newMockContext("Org1MSP")
+
+	current := &VerificationRecord{ID: "REC001", Description: "Manifest", Party: "Org2MSP", Status: "PENDING"}
+	base := time.Date(2024, 3, 1, 8, 0, 0, 0, time.UTC)
+	stub.On("GetState", "REC001").Return(mustMarshal(t, current), nil)
+	stub.On("GetHistoryForKey", "REC001").Return(newHistoryIterator(
+		historyEntry(t, "tx2", base.Add(time.Hour), current),
+		historyEntry(t, "tx1", base, &VerificationRecord{ID: "REC001", Description: "Manifest", Party: "Org1MSP", Status: "CREATED"}),
+	), nil)
+
+	contract := new(HistoryContract)
+	result, err := contract.GetRecordWithHistory(ctx, "REC001")
+
+	assert.NoError(t, err)
+	assert.Equal(t, current, result.Record)
+	assert.Len(t, result.History, 2)
+	assert.Equal(t, "tx2", result.History[0].TxId)
+	assert.Equal(t, "CREATED", result.History[1].Record.Status)
+	t.Log("Both the record and its two history entries were populated")
+}
+
+func TestGetRecordWithHistoryMasksHistory(t *testing.T) {
+	t.Log("Starting TestGetRecordWithHistoryMasksHistory: Verifying history versions are masked like the record")
+	ctx, stub, _ := newMockContext("Org3MSP")
+
+	secret := &VerificationRecord{ID: "REC001", Description: "secret", Party: "Org2MSP", Status: "PENDING", Confidential: true}
+	stub.On("GetState", "REC001").Return(mustMarshal(t, secret), nil)
+	stub.On("GetHistoryForKey", "REC001").Return(newHistoryIterator(
+		historyEntry(t, "tx1", time.Date(2024, 3, 1, 8, 0, 0, 0, time.UTC), secret),
+	), nil)
+
+	contract := new(HistoryContract)
+	result, err := contract.GetRecordWithHistory(ctx, "REC001")
+
+	assert.NoError(t, err)
+	assert.Equal(t, redactedValue, result.Record.Description)
+	assert.Equal(t, redactedValue, result.History[0].Record.Description)
+	t.Log("Neither the record nor its history revealed the description")
+}
+
+func TestGetRecordWithHistoryMissing(t *testing.T) {
+	ctx, stub, _ := newMockContext("Org1MSP")
+	stub.On("GetState", "REC404").Return(nil, nil)
+
+	contract := new(HistoryContract)
+	_, err := contract.GetRecordWithHistory(ctx, "REC404")
+
+	assert.Error(t, err)
+	stub.AssertNotCalled(t, "GetHistoryForKey", "REC404")
+}
+
+func TestGetRecordHistoryExcludingDeletes(t *testing.T) {
+	t.Log("Starting TestGetRecordHistoryExcludingDeletes: Verifying delete markers are left out")
+	ctx, stub, _ := newMockContext("Org1MSP")
+
+	base := time.Date(2024, 3, 1, 8, 0, 0, 0, time.UTC)
+	stub.On("GetHistoryForKey", "REC001").Return(newHistoryIterator(
+		historyEntry(t, "tx3", base.Add(2*time.Hour), &VerificationRecord{ID: "REC001", Status: "CREATED"}),
+		historyEntry(t, "tx2", base.Add(time.Hour), nil),
+		historyEntry(t, "tx1", base, &VerificationRecord{ID: "REC001", Status: "PENDING"}),
+	), nil)
+
+	contract := new(HistoryContract)
+	history, err := contract.GetRecordHistoryExcludingDeletes(ctx, "REC001")
+
+	assert.NoError(t, err)
+	assert.Len(t, history, 2)
+	for _, entry := range history {
+		assert.False(t, entry.IsDelete)
+		assert.NotEqual(t, "tx2", entry.TxId)
+	}
+	t.Log("Only the two written versions remained")
+}
+
+func TestExportHistoryCSV(t *testing.T) {
+	t.Log("Starting TestExportHistoryCSV: Verifying history is rendered as escaped CSV")
+	ctx, stub, _ := newMockContext("Org1MSP")
+
+	base := time.Date(2024, 3, 1, 8, 0, 0, 0, time.UTC)
+	stub.On("GetHistoryForKey", "REC001").Return(newHistoryIterator(
+		historyEntry(t, "tx3", base.Add(2*time.Hour), &VerificationRecord{ID: "REC001", Status: "CREATED", Party: "Org1MSP", Description: `Re-issued "final" draft`}),
+		historyEntry(t, "tx2", base.Add(time.Hour), nil),
+		historyEntry(t, "tx1", base, &VerificationRecord{ID: "REC001", Status: "PENDING", Party: "Org2MSP", Description: "Tea, coffee"}),
+	), nil)
+
+	contract := new(HistoryContract)
+	csvData, err := contract.ExportHistoryCSV(ctx, "REC001")
+
+	expected := "TxId,Timestamp,IsDelete,Status,Party,Description\n" +
+		"tx3,2024-03-01T10:00:00Z,false,CREATED,Org1MSP,\"Re-issued \"\"final\"\" draft\"\n" +
+		"tx2,2024-03-01T09:00:00Z,true,,,\n" +
+		"tx1,2024-03-01T08:00:00Z,false,PENDING,Org2MSP,\"Tea, coffee\"\n"
+	assert.NoError(t, err)
+	assert.Equal(t, expected, csvData)
+	t.Log("Quotes and commas were escaped and the delete marker left blank")
+}
+
+func TestGetRecordVersionCount(t *testing.T) {
+	t.Log("Starting TestGetRecordVersionCount: Verifying history entries are counted with and without deletes")
+	ctx, stub, _ := newMockContext("Org1MSP")
+
+	base := time.Date(2024, 3, 1, 8, 0, 0, 0, time.UTC)
+	// Three writes with a delete marker between the second and third; each call gets a fresh iterator
+	for range 2 {
+		stub.On("GetHistoryForKey", "REC001").Return(newHistoryIterator(
+			historyEntry(t, "tx4", base.Add(3*time.Hour), &VerificationRecord{ID: "REC001", Status: "CREATED"}),
+			historyEntry(t, "tx3", base.Add(2*time.Hour), nil),
+			historyEntry(t, "tx2", base.Add(time.Hour), &VerificationRecord{ID: "REC001", Status: "PENDING"}),
+			historyEntry(t, "tx1", base, &VerificationRecord{ID: "REC001", Status: "CREATED"}),
+		), nil).Once()
+	}
+	stub.On("GetHistoryForKey", "REC404").Return(newHistoryIterator(), nil)
+
+	contract := new(HistoryContract)
+	count, err := contract.GetRecordVersionCount(ctx, "REC001", false)
+	assert.NoError(t, err)
+	assert.Equal(t, 4, count)
+
+	count, err = contract.GetRecordVersionCount(ctx, "REC001", true)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, count)
+
+	_, err = contract.GetRecordVersionCount(ctx, "REC404", false)
+	assert.ErrorIs(t, err, ErrRecordNotFound)
+	t.Log("The three writes and the delete were counted and the unknown ID refused")
+}
+
+func TestGetRecordHistoryPaginated(t *testing.T) {
+	t.Log("Starting TestGetRecordHistoryPaginated: Verifying only the requested window of history is returned")
+	ctx, stub, _ := newMockContext("Org1MSP")
+
+	base := time.Date(2024, 3, 1, 8, 0, 0, 0, time.UTC)
+	var entries []*queryresult.KeyModification
+	for i, status := range []string{"ARCHIVED", "VERIFIED", "PENDING", "CREATED", "CREATED"} {
+		entries = append(entries, historyEntry(t, fmt.Sprintf("tx%d", 5-i), base.Add(time.Duration(5-i)*time.Hour), &VerificationRecord{ID: "REC001", Status: status}))
+	}
+	stub.On("GetHistoryForKey", "REC001").Return(newHistoryIterator(entries...), nil)
+
+	contract := new(HistoryContract)
+	page, err := contract.GetRecordHistoryPaginated(ctx, "REC001", 1, 2)
+
+	assert.NoError(t, err)
+	assert.Len(t, page, 2)
+	assert.Equal(t, "tx4", page[0].TxId)
+	assert.Equal(t, "VERIFIED", page[0].Record.Status)
+	assert.Equal(t, "tx3", page[1].TxId)
+	t.Log("The second and third entries formed the middle page")
+}
+
+func TestGetRecordHistoryPaginatedBounds(t *testing.T) {
+	ctx, _, _ := newMockContext("Org1MSP")
+	contract := new(HistoryContract)
+
+	_, err := contract.GetRecordHistoryPaginated(ctx, "REC001", 0, 0)
+	assert.EqualError(t, err, "limit must be between 1 and 1000, got 0")
+	_, err = contract.GetRecordHistoryPaginated(ctx, "REC001", 0, 1001)
+	assert.EqualError(t, err, "limit must be between 1 and 1000, got 1001")
+	_, err = contract.GetRecordHistoryPaginated(ctx, "REC001", -1, 10)
+	assert.EqualError(t, err, "offset must not be negative, got -1")
+}
+
+func TestHistoryReadersMaskConfidentialDescriptions(t *testing.T) {
+	t.Log("Starting TestHistoryReadersMaskConfidentialDescriptions: Verifying every history view masks for non-owners")
+	base := time.Date(2024, 3, 1, 8, 0, 0, 0, time.UTC)
+	secret := &VerificationRecord{ID: "REC001", Party: "Org2MSP", Status: "CREATED", Description: "secret", Confidential: true}
+	contract := new(HistoryContract)
+
+	readers := map[string]func(ctx *MockTransactionContext) ([]HistoryQueryResult, error){
+		"GetRecordHistory": func(ctx *MockTransactionContext) ([]HistoryQueryResult, error) {
+			return contract.GetRecordHistory(ctx, "REC001")
+		},
+		"GetRecordHistoryBetween": func(ctx *MockTransactionContext) ([]HistoryQueryResult, error) {
+			return contract.GetRecordHistoryBetween(ctx, "REC001", "", "2024-03-02T00:00:00Z")
+		},
+		"GetRecordHistoryExcludingDeletes": func(ctx *MockTransactionContext) ([]HistoryQueryResult, error) {
+			return contract.GetRecordHistoryExcludingDeletes(ctx, "REC001")
+		},
+		"GetRecordHistoryPaginated": func(ctx *MockTransactionContext) ([]HistoryQueryResult, error) {
+			return contract.GetRecordHistoryPaginated(ctx, "REC001", 0, 10)
+		},
+	}
+
+	for name, read := range readers {
+		for mspID, expected := range map[string]string{"Org3MSP": redactedValue, "Org2MSP": "secret"} {
+			ctx, stub, _ := newMockContext(mspID)
+			stub.On("GetHistoryForKey", "REC001").Return(newHistoryIterator(historyEntry(t, "tx1", base, secret)), nil)
+
+			history, err := read(ctx)
+
+			assert.NoError(t, err, name)
+			assert.Len(t, history, 1, name)
+			assert.Equal(t, expected, history[0].Record.Description, "%s as %s", name, mspID)
+		}
+	}
+	t.Log("Non-owners saw the masked description and the owner the original")
+}
@@ -0,0 +1,225 @@
+package main
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// Composite key object types for the secondary indexes.
+// These work on LevelDB as well as CouchDB since they only rely on key ordering.
+const (
+	partyIndex  = "party~id"
+	statusIndex = "status~id"
+)
+
+// indexEntryValue is stored under every index key; the key itself carries the information
+var indexEntryValue = []byte{0x00}
+
+// indexEntry is one secondary index attribute of a record
+type indexEntry struct {
+	name      string
+	attribute string
+}
+
+// indexEntries lists the index attributes of a record; empty attributes are not indexed
+func indexEntries(record *VerificationRecord) []indexEntry {
+	var entries []indexEntry
+	for _, entry := range []indexEntry{
+		{partyIndex, record.Party},
+		{statusIndex, record.Status},
+	} {
+		if entry.attribute != "" {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+// indexRecord writes the party and status index entries for a record.
+// Writing an entry that already exists is harmless, so it is safe to call repeatedly.
+func indexRecord(ctx contractapi.TransactionContextInterface, record *VerificationRecord) error {
+	for _, entry := range indexEntries(record) {
+		key, err := indexKey(ctx, entry, record.ID)
+		if err != nil {
+			return err
+		}
+		if err := ctx.GetStub().PutState(key, indexEntryValue); err != nil {
+			return fmt.Errorf("failed to write %s index entry for %s: %v", entry.name, record.ID, err)
+		}
+	}
+	return nil
+}
+
+// unindexRecord removes the party and status index entries of a record
+func unindexRecord(ctx contractapi.TransactionContextInterface, record *VerificationRecord) error {
+	for _, entry := range indexEntries(record) {
+		key, err := indexKey(ctx, entry, record.ID)
+		if err != nil {
+			return err
+		}
+		if err := ctx.GetStub().DelState(key); err != nil {
+			return fmt.Errorf("failed to remove %s index entry for %s: %v", entry.name, record.ID, err)
+		}
+	}
+	return nil
+}
+
+// reindexRecord moves a record's index entries from its previous version to the new one.
+// Entries whose attribute did not change are left alone so plain updates cost no extra writes.
+func reindexRecord(ctx contractapi.TransactionContextInterface, previous *VerificationRecord, record *VerificationRecord) error {
+	if previous == nil {
+		return indexRecord(ctx, record)
+	}
+
+	stale := &VerificationRecord{ID: previous.ID}
+	fresh := &VerificationRecord{ID: record.ID}
+	if previous.Party != record.Party {
+		stale.Party, fresh.Party = previous.Party, record.Party
+	}
+	if previous.Status != record.Status {
+		stale.Status, fresh.Status = previous.Status, record.Status
+	}
+
+	if err := unindexRecord(ctx, stale); err != nil {
+		return err
+	}
+	return indexRecord(ctx, fresh)
+}
+
+// indexKey builds the composite key of one index entry
+func indexKey(ctx contractapi.TransactionContextInterface, entry indexEntry, id string) (string, error) {
+	key, err := ctx.GetStub().CreateCompositeKey(entry.name, []string{entry.attribute, id})
+	if err != nil {
+		return "", fmt.Errorf("failed to create %s index key for %s: %v", entry.name, id, err)
+	}
+	return key, nil
+}
+
+// QueryRecordsByPartyIndexed returns the records owned by a party using the party~id composite index.
+// Unlike QueryRecordsByParty it needs no rich queries, so it also works on LevelDB.
+// Soft-deleted records are left out, and confidential descriptions are masked for callers other than the
+// owner and the admin MSP.
+func (s *HistoryContract) QueryRecordsByPartyIndexed(ctx contractapi.TransactionContextInterface, party string) ([]*VerificationRecord, error) {
+	if party == "" {
+		return nil, fmt.Errorf("party must not be empty")
+	}
+
+	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(partyIndex, []string{party})
+	if err != nil {
+		return nil, err
+	}
+	defer resultsIterator.Close()
+
+	records := []*VerificationRecord{}
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+
+		_, attributes, err := ctx.GetStub().SplitCompositeKey(queryResponse.Key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to split index key %s: %v", queryResponse.Key, err)
+		}
+		if len(attributes) != 2 {
+			return nil, fmt.Errorf("malformed %s index key %s", partyIndex, queryResponse.Key)
+		}
+
+		record, err := s.readRecord(ctx, attributes[1])
+		if err != nil {
+			return nil, err
+		}
+		if record.Deleted {
+			continue
+		}
+		records = append(records, record)
+	}
+
+	if err := redactConfidential(ctx, records...); err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+// CountRecordsByStatus counts the records in a status found through the status~id index, so no rich
+// queries are needed. Like GetTotalQuantityByStatus it leaves soft-deleted records out, which keeps
+// counts and totals over the same status consistent. A status without records counts as zero.
+func (s *HistoryContract) CountRecordsByStatus(ctx contractapi.TransactionContextInterface, status string) (int, error) {
+	records, err := s.recordsByStatusIndex(ctx, status)
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for _, record := range records {
+		if !record.Deleted {
+			count++
+		}
+	}
+
+	return count, nil
+}
+
+// GetTotalQuantityByStatus sums the Quantity of every record in a status, found through the status~id index.
+// Soft-deleted records are left out. The sum fails rather than wrapping around if it would overflow int64.
+func (s *HistoryContract) GetTotalQuantityByStatus(ctx contractapi.TransactionContextInterface, status string) (int64, error) {
+	records, err := s.recordsByStatusIndex(ctx, status)
+	if err != nil {
+		return 0, err
+	}
+
+	var total int64
+	for _, record := range records {
+		if record.Deleted {
+			continue
+		}
+		if record.Quantity > math.MaxInt64-total {
+			return 0, fmt.Errorf("total quantity of %s records overflows int64", status)
+		}
+		total += record.Quantity
+	}
+
+	return total, nil
+}
+
+// recordsByStatusIndex loads every record in a known status through the status~id index, without masking
+func (s *HistoryContract) recordsByStatusIndex(ctx contractapi.TransactionContextInterface, status string) ([]*VerificationRecord, error) {
+	if status == "" {
+		return nil, fmt.Errorf("status must not be empty")
+	}
+	if err := requireKnownStatus(ctx, status); err != nil {
+		return nil, err
+	}
+
+	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(statusIndex, []string{status})
+	if err != nil {
+		return nil, err
+	}
+	defer resultsIterator.Close()
+
+	records := []*VerificationRecord{}
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+
+		_, attributes, err := ctx.GetStub().SplitCompositeKey(queryResponse.Key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to split index key %s: %v", queryResponse.Key, err)
+		}
+		if len(attributes) != 2 {
+			return nil, fmt.Errorf("malformed %s index key %s", statusIndex, queryResponse.Key)
+		}
+
+		record, err := s.readRecord(ctx, attributes[1])
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, record)
+	}
+
+	return records, nil
+}
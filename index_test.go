@@ -0,0 +1,140 @@
+package main
+
+import (
+	"math"
+	"testing"
+
+	"github.com/hyperledger/fabric-protos-go/ledger/queryresult"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestQueryRecordsByPartyIndexed(t *testing.T) {
+	t.Log("Starting TestQueryRecordsByPartyIndexed: Verifying party index entries resolve to their records")
+	ctx, stub, _ := newMockContext("Org2MSP")
+
+	rec1 := VerificationRecord{ID: "REC001", Party: "Org2MSP", Status: "CREATED"}
+	rec3 := VerificationRecord{ID: "REC003", Party: "Org2MSP", Status: "VERIFIED"}
+	stub.On("GetStateByPartialCompositeKey", partyIndex, []string{"Org2MSP"}).Return(newKVIterator(
+		&queryresult.KV{Key: compositeKey(partyIndex, "Org2MSP", "REC001"), Value: indexEntryValue},
+		&queryresult.KV{Key: compositeKey(partyIndex, "Org2MSP", "REC003"), Value: indexEntryValue},
+	), nil)
+	stub.On("GetState", "REC001").Return(mustMarshal(t, rec1), nil)
+	stub.On("GetState", "REC003").Return(mustMarshal(t, rec3), nil)
+
+	contract := new(HistoryContract)
+	records, err := contract.QueryRecordsByPartyIndexed(ctx, "Org2MSP")
+
+	assert.NoError(t, err)
+	assert.Equal(t, []*VerificationRecord{&rec1, &rec3}, records)
+	t.Log("Both indexed records were resolved without a rich query")
+}
+
+func TestUpdateRecordMovesStatusIndexEntry(t *testing.T) {
+	t.Log("Starting TestUpdateRecordMovesStatusIndexEntry: Verifying a status change rewrites only the status index")
+	ctx, stub, _ := newMockContext("Org2MSP")
+
+	existing := VerificationRecord{ID: "REC001", Party: "Org2MSP", Status: "CREATED", Version: 1}
+	stub.On("GetState", "REC001").Return(mustMarshal(t, existing), nil)
+	stub.On("GetState", compositeKey(configObjectType, configWorkflowGraph)).Return(nil, nil)
+	stub.On("GetTxTimestamp").Return(timestamppb.Now(), nil)
+	stub.On("PutState", "REC001", mock.Anything).Return(nil)
+	stub.On("DelState", compositeKey(statusIndex, "CREATED", "REC001")).Return(nil)
+	stub.On("PutState", compositeKey(statusIndex, "PENDING", "REC001"), indexEntryValue).Return(nil)
+	stub.On("SetEvent", "RecordUpdated", mock.Anything).Return(nil)
+
+	contract := new(HistoryContract)
+	err := contract.UpdateRecord(ctx, "REC001", "Submitted", "PENDING", "", 1)
+
+	assert.NoError(t, err)
+	stub.AssertExpectations(t)
+	stub.AssertNotCalled(t, "PutState", compositeKey(partyIndex, "Org2MSP", "REC001"), indexEntryValue)
+}
+
+func TestDeleteRecordRemovesIndexEntries(t *testing.T) {
+	t.Log("Starting TestDeleteRecordRemovesIndexEntries: Verifying deletion cleans up the party and status index")
+	ctx, stub, _ := newMockContext("Org2MSP")
+
+	stub.On("GetState", "REC001").Return(mustMarshal(t, VerificationRecord{ID: "REC001", Party: "Org2MSP", Status: "REJECTED"}), nil)
+	stub.On("DelState", "REC001").Return(nil)
+	stub.On("DelState", compositeKey(partyIndex, "Org2MSP", "REC001")).Return(nil)
+	stub.On("DelState", compositeKey(statusIndex, "REJECTED", "REC001")).Return(nil)
+
+	contract := new(HistoryContract)
+	err := contract.DeleteRecord(ctx, "REC001")
+
+	assert.NoError(t, err)
+	stub.AssertExpectations(t)
+	t.Log("Record and both index entries were removed")
+}
+
+func TestCountRecordsByStatus(t *testing.T) {
+	t.Log("Starting TestCountRecordsByStatus: Verifying status index entries are counted per status")
+	ctx, stub, _ := newMockContext("Org1MSP")
+	stub.On("GetState", compositeKey(configObjectType, configWorkflowGraph)).Return(nil, nil)
+
+	// Index entries for a ledger holding two PENDING records, one VERIFIED and none REJECTED,
+	// plus a soft-deleted PENDING record that is not counted
+	entries := map[string][]string{
+		"PENDING":  {"REC001", "REC003", "REC004"},
+		"VERIFIED": {"REC002"},
+		"REJECTED": {},
+	}
+	for status, ids := range entries {
+		var kvs []*queryresult.KV
+		for _, id := range ids {
+			kvs = append(kvs, &queryresult.KV{Key: compositeKey(statusIndex, status, id), Value: indexEntryValue})
+			record := VerificationRecord{ID: id, Status: status, Deleted: id == "REC004"}
+			stub.On("GetState", id).Return(mustMarshal(t, record), nil)
+		}
+		stub.On("GetStateByPartialCompositeKey", statusIndex, []string{status}).Return(newKVIterator(kvs...), nil)
+	}
+
+	contract := new(HistoryContract)
+	for status, expected := range map[string]int{"PENDING": 2, "VERIFIED": 1, "REJECTED": 0} {
+		count, err := contract.CountRecordsByStatus(ctx, status)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, count, status)
+	}
+	t.Log("Each status reported its own count without the soft-deleted record, with zero for an unused status")
+}
+
+func TestGetTotalQuantityByStatus(t *testing.T) {
+	t.Log("Starting TestGetTotalQuantityByStatus: Verifying quantities of one status are summed")
+	ctx, stub, _ := newMockContext("Org1MSP")
+	stub.On("GetState", compositeKey(configObjectType, configWorkflowGraph)).Return(nil, nil)
+
+	stub.On("GetStateByPartialCompositeKey", statusIndex, []string{"VERIFIED"}).Return(newKVIterator(
+		&queryresult.KV{Key: compositeKey(statusIndex, "VERIFIED", "REC001"), Value: indexEntryValue},
+		&queryresult.KV{Key: compositeKey(statusIndex, "VERIFIED", "REC002"), Value: indexEntryValue},
+		&queryresult.KV{Key: compositeKey(statusIndex, "VERIFIED", "REC003"), Value: indexEntryValue},
+	), nil)
+	stub.On("GetState", "REC001").Return(mustMarshal(t, VerificationRecord{ID: "REC001", Status: "VERIFIED", Quantity: 120}), nil)
+	stub.On("GetState", "REC002").Return(mustMarshal(t, VerificationRecord{ID: "REC002", Status: "VERIFIED", Quantity: 30}), nil)
+	stub.On("GetState", "REC003").Return(mustMarshal(t, VerificationRecord{ID: "REC003", Status: "VERIFIED", Quantity: 500, Deleted: true}), nil)
+
+	contract := new(HistoryContract)
+	total, err := contract.GetTotalQuantityByStatus(ctx, "VERIFIED")
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(150), total)
+	t.Log("The soft-deleted record was left out of the total")
+}
+
+func TestGetTotalQuantityByStatusOverflow(t *testing.T) {
+	ctx, stub, _ := newMockContext("Org1MSP")
+	stub.On("GetState", compositeKey(configObjectType, configWorkflowGraph)).Return(nil, nil)
+
+	stub.On("GetStateByPartialCompositeKey", statusIndex, []string{"PENDING"}).Return(newKVIterator(
+		&queryresult.KV{Key: compositeKey(statusIndex, "PENDING", "REC001"), Value: indexEntryValue},
+		&queryresult.KV{Key: compositeKey(statusIndex, "PENDING", "REC002"), Value: indexEntryValue},
+	), nil)
+	stub.On("GetState", "REC001").Return(mustMarshal(t, VerificationRecord{ID: "REC001", Status: "PENDING", Quantity: math.MaxInt64}), nil)
+	stub.On("GetState", "REC002").Return(mustMarshal(t, VerificationRecord{ID: "REC002", Status: "PENDING", Quantity: 1}), nil)
+
+	contract := new(HistoryContract)
+	_, err := contract.GetTotalQuantityByStatus(ctx, "PENDING")
+
+	assert.EqualError(t, err, "total quantity of PENDING records overflows int64")
+}
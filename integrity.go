@@ -0,0 +1,194 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// RecordWithHash bundles a record with its canonical content hash
+type RecordWithHash struct {
+	Record *VerificationRecord `json:"record"`
+	Hash   string              `json:"hash"`
+}
+
+// GetRecordWithHash returns a record together with its canonical SHA-256 hash so clients can
+// track integrity without recomputing it. The hash is taken over the stored content, before any masking.
+func (s *HistoryContract) GetRecordWithHash(ctx contractapi.TransactionContextInterface, id string) (*RecordWithHash, error) {
+	record, err := s.readRecord(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	hash, err := computeRecordHash(record)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := redactConfidential(ctx, record); err != nil {
+		return nil, err
+	}
+
+	return &RecordWithHash{Record: record, Hash: hash}, nil
+}
+
+// VerifyRecordIntegrity recomputes a record's hash from its stored fields and reports whether it
+// matches the stored Hash. A mismatch means the content changed without going through the contract,
+// e.g. a buggy importer writing the key directly.
+func (s *HistoryContract) VerifyRecordIntegrity(ctx contractapi.TransactionContextInterface, id string) (bool, error) {
+	record, err := s.readRecord(ctx, id)
+	if err != nil {
+		return false, err
+	}
+	if record.Hash == "" {
+		return false, fmt.Errorf("record %s has no integrity hash", id)
+	}
+
+	hash, err := computeRecordHash(record)
+	if err != nil {
+		return false, err
+	}
+	return hash == record.Hash, nil
+}
+
+// DetectTampering walks a record's history and returns the TxIds of versions whose stored Hash does not
+// match their own content, i.e. versions written with a wrong or forged hash. Delete markers and
+// versions written before hashes were introduced carry no hash and are skipped.
+func (s *HistoryContract) DetectTampering(ctx contractapi.TransactionContextInterface, id string) ([]string, error) {
+	history, err := s.readRecordHistory(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	tampered := []string{}
+	for _, entry := range history {
+		if entry.IsDelete || entry.Record == nil || entry.Record.Hash == "" {
+			continue
+		}
+
+		hash, err := computeRecordHash(entry.Record)
+		if err != nil {
+			return nil, err
+		}
+		if hash != entry.Record.Hash {
+			tampered = append(tampered, entry.TxId)
+		}
+	}
+	return tampered, nil
+}
+
+// VerifyHashChain walks a record's history from oldest to newest and checks that every version's PrevHash
+// is the content hash of the version before it. The first version, and the first one after a delete,
+// must carry no PrevHash. Any break means a version was altered or inserted outside the contract.
+func (s *HistoryContract) VerifyHashChain(ctx contractapi.TransactionContextInterface, id string) (bool, error) {
+	history, err := s.getChronologicalHistory(ctx, id)
+	if err != nil {
+		return false, err
+	}
+	if len(history) == 0 {
+		return false, fmt.Errorf("record %s has no history: %w", id, ErrRecordNotFound)
+	}
+
+	expected := ""
+	for _, entry := range history {
+		if entry.IsDelete || entry.Record == nil {
+			expected = ""
+			continue
+		}
+		if entry.Record.PrevHash != expected {
+			return false, nil
+		}
+
+		expected, err = computeRecordHash(entry.Record)
+		if err != nil {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// computeRecordHash returns the hex SHA-256 of the record's canonical JSON form, leaving out the stored Hash.
+// encoding/json emits struct fields in declaration order and sorts map keys, so the
+// encoding is deterministic across peers.
+func computeRecordHash(record *VerificationRecord) (string, error) {
+	unhashed := *record
+	unhashed.Hash = ""
+
+	canonical, err := json.Marshal(unhashed)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal record %s for hashing: %v", record.ID, err)
+	}
+
+	sum := sha256.Sum256(canonical)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// ComputeMerkleRoot builds a Merkle tree over the canonical hashes of every record and returns its root
+// as hex. Leaves are sorted so the root only depends on the ledger content, not on iteration order.
+// Each parent is SHA-256(left || right) over the raw digests; an odd node is promoted to the next level.
+// The root is also stored under the merkleRoot config entry so a later run can be compared against it;
+// because that overwrites the stored commitment, only the admin MSP may call it.
+func (s *HistoryContract) ComputeMerkleRoot(ctx contractapi.TransactionContextInterface) (string, error) {
+	if err := requireAdmin(ctx); err != nil {
+		return "", err
+	}
+
+	records, err := s.getAllRecords(ctx)
+	if err != nil {
+		return "", err
+	}
+	if len(records) == 0 {
+		return "", fmt.Errorf("cannot compute a Merkle root over an empty ledger")
+	}
+
+	leaves := make([]string, 0, len(records))
+	for _, record := range records {
+		hash, err := computeRecordHash(record)
+		if err != nil {
+			return "", err
+		}
+		leaves = append(leaves, hash)
+	}
+	sort.Strings(leaves)
+
+	level := make([][]byte, 0, len(leaves))
+	for _, leaf := range leaves {
+		digest, err := hex.DecodeString(leaf)
+		if err != nil {
+			return "", err
+		}
+		level = append(level, digest)
+	}
+
+	for len(level) > 1 {
+		next := make([][]byte, 0, (len(level)+1)/2)
+		for i := 0; i < len(level); i += 2 {
+			if i+1 == len(level) {
+				next = append(next, level[i])
+				continue
+			}
+			sum := sha256.Sum256(append(append([]byte{}, level[i]...), level[i+1]...))
+			next = append(next, sum[:])
+		}
+		level = next
+	}
+
+	root := hex.EncodeToString(level[0])
+	if err := putConfigValue(ctx, configMerkleRoot, root); err != nil {
+		return "", err
+	}
+	return root, nil
+}
+
+// isSHA256Hex reports whether value is a 64 character hex encoded SHA-256 digest
+func isSHA256Hex(value string) bool {
+	if len(value) != hex.EncodedLen(sha256.Size) {
+		return false
+	}
+	_, err := hex.DecodeString(value)
+	return err == nil
+}
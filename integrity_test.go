@@ -0,0 +1,237 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestGetRecordWithHashIsStable(t *testing.T) {
+	t.Log("Starting TestGetRecordWithHashIsStable: Verifying the canonical hash does not depend on encoding order")
+	ctx, stub, _ := newMockContext("Org1MSP")
+
+	// The same content stored with metadata keys in a different order must hash identically
+	stub.On("GetState", "REC001").Return([]byte(`{"id":"REC001","status":"CREATED","metadata":{"a":"1","b":"2"}}`), nil)
+	stub.On("GetState", "REC002").Return([]byte(`{"metadata":{"b":"2","a":"1"},"status":"CREATED","id":"REC001"}`), nil)
+
+	contract := new(HistoryContract)
+	first, err := contract.GetRecordWithHash(ctx, "REC001")
+	assert.NoError(t, err)
+	second, err := contract.GetRecordWithHash(ctx, "REC002")
+	assert.NoError(t, err)
+
+	assert.Len(t, first.Hash, 64)
+	assert.Equal(t, first.Hash, second.Hash)
+	assert.Equal(t, "REC001", first.Record.ID)
+
+	expected, err := computeRecordHash(first.Record)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, first.Hash)
+	t.Log("Both encodings produced the same hash")
+}
+
+// merkleParent hashes two hex encoded child digests the way ComputeMerkleRoot does
+func merkleParent(t *testing.T, left string, right string) string {
+	l, err := hex.DecodeString(left)
+	assert.NoError(t, err)
+	r, err := hex.DecodeString(right)
+	assert.NoError(t, err)
+	sum := sha256.Sum256(append(l, r...))
+	return hex.EncodeToString(sum[:])
+}
+
+func TestComputeMerkleRootIsStable(t *testing.T) {
+	t.Log("Starting TestComputeMerkleRootIsStable: Verifying the root is deterministic and stored")
+	records := []VerificationRecord{
+		{ID: "REC001", Party: "Org1MSP", Status: "CREATED"},
+		{ID: "REC002", Party: "Org2MSP", Status: "PENDING"},
+		{ID: "REC003", Party: "Org1MSP", Status: "VERIFIED"},
+	}
+
+	var leaves []string
+	for i := range records {
+		hash, err := computeRecordHash(&records[i])
+		assert.NoError(t, err)
+		leaves = append(leaves, hash)
+	}
+	sort.Strings(leaves)
+	// Three leaves: the first two are paired and the third is promoted
+	expected := merkleParent(t, merkleParent(t, leaves[0], leaves[1]), leaves[2])
+
+	contract := new(HistoryContract)
+	for _, order := range [][]VerificationRecord{records, {records[2], records[0], records[1]}} {
+		ctx, stub, _ := newMockContext(AdminMSPID)
+		stub.On("GetStateByRange", "", "").Return(newStateIterator(t, order...), nil)
+		stub.On("PutState", compositeKey(configObjectType, configMerkleRoot), []byte(expected)).Return(nil)
+
+		root, err := contract.ComputeMerkleRoot(ctx)
+
+		assert.NoError(t, err)
+		assert.Equal(t, expected, root)
+		stub.AssertExpectations(t)
+	}
+	t.Log("The same records produced the same root regardless of iteration order")
+}
+
+func TestComputeMerkleRootChangesWithContent(t *testing.T) {
+	t.Log("Starting TestComputeMerkleRootChangesWithContent: Verifying a changed record changes the root")
+	contract := new(HistoryContract)
+	roots := make([]string, 0, 2)
+	for _, status := range []string{"PENDING", "REJECTED"} {
+		ctx, stub, _ := newMockContext(AdminMSPID)
+		stub.On("GetStateByRange", "", "").Return(newStateIterator(t,
+			VerificationRecord{ID: "REC001", Status: "CREATED"},
+			VerificationRecord{ID: "REC002", Status: status},
+		), nil)
+		stub.On("PutState", compositeKey(configObjectType, configMerkleRoot), mock.Anything).Return(nil)
+
+		root, err := contract.ComputeMerkleRoot(ctx)
+		assert.NoError(t, err)
+		roots = append(roots, root)
+	}
+
+	assert.NotEqual(t, roots[0], roots[1])
+	t.Log("Changing one record's status produced a different root")
+}
+
+func TestVerifyRecordIntegrityValid(t *testing.T) {
+	t.Log("Starting TestVerifyRecordIntegrityValid: Verifying a record written by the contract passes")
+	ctx, stub, _ := newMockContext("Org1MSP")
+
+	var stored []byte
+	stub.On("GetState", "REC001").Return(nil, nil).Once()
+	stub.On("PutState", "REC001", mock.Anything).Run(func(args mock.Arguments) {
+		stored = args.Get(1).([]byte)
+	}).Return(nil)
+	stub.On("PutState", mock.Anything, indexEntryValue).Return(nil)
+
+	contract := new(HistoryContract)
+	assert.NoError(t, contract.putRecord(ctx, &VerificationRecord{ID: "REC001", Description: "Grade A tea", Party: "Org1MSP", Status: "CREATED"}))
+
+	stub.On("GetState", "REC001").Return(stored, nil)
+	valid, err := contract.VerifyRecordIntegrity(ctx, "REC001")
+
+	assert.NoError(t, err)
+	assert.True(t, valid)
+	t.Log("The stored hash matched the recomputed one")
+}
+
+func TestVerifyRecordIntegrityTampered(t *testing.T) {
+	t.Log("Starting TestVerifyRecordIntegrityTampered: Verifying an altered stored hash is detected")
+	ctx, stub, _ := newMockContext("Org1MSP")
+
+	record := &VerificationRecord{ID: "REC001", Description: "Grade A tea", Party: "Org1MSP", Status: "CREATED"}
+	hash, err := computeRecordHash(record)
+	assert.NoError(t, err)
+	// Flip the first digit so the stored hash no longer matches the content
+	record.Hash = "0" + hash[1:]
+	if hash[0] == '0' {
+		record.Hash = "1" + hash[1:]
+	}
+	stub.On("GetState", "REC001").Return(mustMarshal(t, record), nil)
+
+	contract := new(HistoryContract)
+	valid, err := contract.VerifyRecordIntegrity(ctx, "REC001")
+
+	assert.NoError(t, err)
+	assert.False(t, valid)
+	t.Log("The altered hash was reported as an integrity failure")
+}
+
+func TestDetectTampering(t *testing.T) {
+	t.Log("Starting TestDetectTampering: Verifying versions with a forged hash are reported")
+	ctx, stub, _ := newMockContext("Org1MSP")
+
+	good := &VerificationRecord{ID: "REC001", Description: "Manifest", Party: "Org1MSP", Status: "CREATED", Version: 1}
+	goodHash, err := computeRecordHash(good)
+	assert.NoError(t, err)
+	good.Hash = goodHash
+
+	// The hash of the good version no longer matches once the status is changed behind its back
+	forged := *good
+	forged.Status = "VERIFIED"
+	forged.Version = 2
+
+	stub.On("GetHistoryForKey", "REC001").Return(newHistoryIterator(
+		historyEntry(t, "tx2", time.Now(), &forged),
+		historyEntry(t, "tx1", time.Now().Add(-time.Hour), good),
+	), nil)
+
+	contract := new(HistoryContract)
+	tampered, err := contract.DetectTampering(ctx, "REC001")
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"tx2"}, tampered)
+	t.Log("Only the inconsistent version was flagged")
+}
+
+// chainedVersions builds versions of REC001 whose PrevHash links each one to its predecessor
+func chainedVersions(t *testing.T, statuses ...string) []*VerificationRecord {
+	var versions []*VerificationRecord
+	prevHash := ""
+	for i, status := range statuses {
+		version := &VerificationRecord{ID: "REC001", Description: "Manifest", Party: "Org1MSP", Status: status, Version: i + 1, PrevHash: prevHash}
+		hash, err := computeRecordHash(version)
+		assert.NoError(t, err)
+		version.Hash = hash
+		prevHash = hash
+		versions = append(versions, version)
+	}
+	return versions
+}
+
+func TestVerifyHashChain(t *testing.T) {
+	t.Log("Starting TestVerifyHashChain: Verifying intact chains pass and altered ones fail")
+	base := time.Date(2024, 3, 1, 8, 0, 0, 0, time.UTC)
+	contract := new(HistoryContract)
+
+	versions := chainedVersions(t, "CREATED", "PENDING", "VERIFIED")
+	ctx, stub, _ := newMockContext("Org1MSP")
+	stub.On("GetHistoryForKey", "REC001").Return(newHistoryIterator(
+		historyEntry(t, "tx3", base.Add(2*time.Hour), versions[2]),
+		historyEntry(t, "tx2", base.Add(time.Hour), versions[1]),
+		historyEntry(t, "tx1", base, versions[0]),
+	), nil)
+	valid, err := contract.VerifyHashChain(ctx, "REC001")
+	assert.NoError(t, err)
+	assert.True(t, valid)
+
+	// Rewriting the middle version breaks the link from the version after it
+	altered := *versions[1]
+	altered.Description = "Rewritten"
+	ctx, stub, _ = newMockContext("Org1MSP")
+	stub.On("GetHistoryForKey", "REC001").Return(newHistoryIterator(
+		historyEntry(t, "tx3", base.Add(2*time.Hour), versions[2]),
+		historyEntry(t, "tx2", base.Add(time.Hour), &altered),
+		historyEntry(t, "tx1", base, versions[0]),
+	), nil)
+	valid, err = contract.VerifyHashChain(ctx, "REC001")
+	assert.NoError(t, err)
+	assert.False(t, valid)
+	t.Log("The untouched chain verified and the rewritten one did not")
+}
+
+func TestPutRecordLinksPrevHash(t *testing.T) {
+	ctx, stub, _ := newMockContext("Org1MSP")
+
+	previous := chainedVersions(t, "CREATED")[0]
+	stub.On("GetState", "REC001").Return(mustMarshal(t, previous), nil)
+	var written VerificationRecord
+	stub.On("PutState", "REC001", mock.Anything).Run(func(args mock.Arguments) {
+		_ = json.Unmarshal(args.Get(1).([]byte), &written)
+	}).Return(nil)
+
+	contract := new(HistoryContract)
+	next := *previous
+	next.Description = "Inspected"
+	assert.NoError(t, contract.putRecord(ctx, &next))
+
+	assert.Equal(t, previous.Hash, written.PrevHash)
+	assert.Equal(t, 2, written.Version)
+}
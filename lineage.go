@@ -0,0 +1,87 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// LinkRecords records that childID derives from parentID. Both records must exist, and only the
+// child's owner or the admin MSP may link it. Links that would make a record its own ancestor are refused.
+func (s *HistoryContract) LinkRecords(ctx contractapi.TransactionContextInterface, childID string, parentID string) error {
+	if childID == parentID {
+		return fmt.Errorf("record %s cannot be its own parent", childID)
+	}
+
+	child, err := s.readRecord(ctx, childID)
+	if err != nil {
+		return err
+	}
+	if _, err := s.readRecord(ctx, parentID); err != nil {
+		return err
+	}
+
+	if _, err := authorizeOwner(ctx, child, "link"); err != nil {
+		return err
+	}
+
+	ancestors, err := s.walkLineage(ctx, parentID)
+	if err != nil {
+		return err
+	}
+	for _, ancestor := range ancestors {
+		if ancestor.ID == childID {
+			return fmt.Errorf("linking %s to %s would create a lineage cycle", childID, parentID)
+		}
+	}
+
+	timestamp, err := txTimestampString(ctx)
+	if err != nil {
+		return err
+	}
+
+	child.ParentID = parentID
+	child.Timestamp = timestamp
+	return s.putRecord(ctx, child)
+}
+
+// GetLineage returns the ancestry of a record, nearest parent first and the root last.
+// A record without a parent has an empty lineage. Confidential descriptions are masked as in GetRecord.
+func (s *HistoryContract) GetLineage(ctx contractapi.TransactionContextInterface, id string) ([]*VerificationRecord, error) {
+	ancestors, err := s.walkLineage(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := redactConfidential(ctx, ancestors...); err != nil {
+		return nil, err
+	}
+	return ancestors, nil
+}
+
+// walkLineage follows ParentID links upwards from a record and returns its ancestors without masking.
+// Links can be written outside LinkRecords (e.g. by BatchImport), so revisiting a record is reported as a cycle.
+func (s *HistoryContract) walkLineage(ctx contractapi.TransactionContextInterface, id string) ([]*VerificationRecord, error) {
+	record, err := s.readRecord(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	visited := map[string]bool{id: true}
+	ancestors := []*VerificationRecord{}
+	for record.ParentID != "" {
+		if visited[record.ParentID] {
+			return nil, fmt.Errorf("lineage of record %s contains a cycle at %s", id, record.ParentID)
+		}
+		visited[record.ParentID] = true
+
+		parent, err := s.readRecord(ctx, record.ParentID)
+		if err != nil {
+			return nil, err
+		}
+		ancestors = append(ancestors, parent)
+		record = parent
+	}
+
+	return ancestors, nil
+}
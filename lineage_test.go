@@ -0,0 +1,63 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestGetLineageThreeLevels(t *testing.T) {
+	t.Log("Starting TestGetLineageThreeLevels: Verifying ancestry is returned nearest parent first")
+	ctx, stub, _ := newMockContext("Org1MSP")
+
+	draft := VerificationRecord{ID: "REC001", Description: "Contract Draft", Status: "VERIFIED"}
+	manifest := VerificationRecord{ID: "REC002", Description: "Shipping Manifest", Status: "VERIFIED", ParentID: "REC001"}
+	receipt := VerificationRecord{ID: "REC003", Description: "Delivery Receipt", Status: "PENDING", ParentID: "REC002"}
+	stub.On("GetState", "REC001").Return(mustMarshal(t, draft), nil)
+	stub.On("GetState", "REC002").Return(mustMarshal(t, manifest), nil)
+	stub.On("GetState", "REC003").Return(mustMarshal(t, receipt), nil)
+
+	contract := new(HistoryContract)
+	lineage, err := contract.GetLineage(ctx, "REC003")
+
+	assert.NoError(t, err)
+	assert.Equal(t, []*VerificationRecord{&manifest, &draft}, lineage)
+	t.Log("The receipt's lineage walked up through the manifest to the contract")
+}
+
+func TestGetLineageDetectsCycle(t *testing.T) {
+	t.Log("Starting TestGetLineageDetectsCycle: Verifying a parent loop is reported instead of walked forever")
+	ctx, stub, _ := newMockContext("Org1MSP")
+
+	stub.On("GetState", "REC001").Return(mustMarshal(t, VerificationRecord{ID: "REC001", ParentID: "REC002"}), nil)
+	stub.On("GetState", "REC002").Return(mustMarshal(t, VerificationRecord{ID: "REC002", ParentID: "REC001"}), nil)
+
+	contract := new(HistoryContract)
+	_, err := contract.GetLineage(ctx, "REC001")
+
+	assert.EqualError(t, err, "lineage of record REC001 contains a cycle at REC001")
+}
+
+func TestLinkRecords(t *testing.T) {
+	t.Log("Starting TestLinkRecords: Verifying a child is linked to an existing parent")
+	ctx, stub, _ := newMockContext("Org2MSP")
+
+	stub.On("GetState", "REC001").Return(mustMarshal(t, VerificationRecord{ID: "REC001", Party: "Org1MSP"}), nil)
+	stub.On("GetState", "REC002").Return(mustMarshal(t, VerificationRecord{ID: "REC002", Party: "Org2MSP"}), nil)
+	stub.On("GetTxTimestamp").Return(timestamppb.New(time.Date(2024, 3, 1, 8, 0, 0, 0, time.UTC)), nil)
+	var written VerificationRecord
+	stub.On("PutState", "REC002", mock.Anything).Run(func(args mock.Arguments) {
+		_ = json.Unmarshal(args.Get(1).([]byte), &written)
+	}).Return(nil)
+
+	contract := new(HistoryContract)
+	err := contract.LinkRecords(ctx, "REC002", "REC001")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "REC001", written.ParentID)
+	assert.Equal(t, "2024-03-01T08:00:00Z", written.Timestamp)
+}
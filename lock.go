@@ -0,0 +1,70 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// RecordLockEvent is the payload of the RecordLocked and RecordUnlocked chaincode events
+type RecordLockEvent struct {
+	ID        string `json:"id"`
+	Party     string `json:"party"` // The organization that locked or unlocked the record
+	Timestamp string `json:"timestamp"`
+}
+
+// LockRecord freezes a record, e.g. while a dispute is resolved, and emits a RecordLocked event.
+// Every change is refused until UnlockRecord lifts the lock. Only the owning party or the admin MSP may lock it.
+func (s *HistoryContract) LockRecord(ctx contractapi.TransactionContextInterface, id string) error {
+	return s.setLocked(ctx, id, true)
+}
+
+// UnlockRecord lifts the lock placed by LockRecord and emits a RecordUnlocked event.
+// Only the owning party or the admin MSP may unlock it.
+func (s *HistoryContract) UnlockRecord(ctx contractapi.TransactionContextInterface, id string) error {
+	return s.setLocked(ctx, id, false)
+}
+
+// setLocked flips the lock of a record after checking ownership and the current state
+func (s *HistoryContract) setLocked(ctx contractapi.TransactionContextInterface, id string, locked bool) error {
+	record, err := s.readRecord(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := ensureNotSealed(record); err != nil {
+		return err
+	}
+
+	action, event := "unlock", "RecordUnlocked"
+	if locked {
+		action, event = "lock", "RecordLocked"
+	}
+	if record.Locked == locked {
+		return fmt.Errorf("record %s is already %sed", id, action)
+	}
+
+	clientIdentity, err := authorizeOwner(ctx, record, action)
+	if err != nil {
+		return err
+	}
+
+	timestamp, err := txTimestampString(ctx)
+	if err != nil {
+		return err
+	}
+	record.Locked = locked
+	record.Timestamp = timestamp
+	if err := s.putRecord(ctx, record); err != nil {
+		return err
+	}
+
+	eventJSON, err := json.Marshal(RecordLockEvent{ID: id, Party: clientIdentity, Timestamp: timestamp})
+	if err != nil {
+		return err
+	}
+	if err := ctx.GetStub().SetEvent(event, eventJSON); err != nil {
+		return fmt.Errorf("failed to emit %s event for %s: %v", event, id, err)
+	}
+	return nil
+}
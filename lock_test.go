@@ -0,0 +1,73 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestLockRecordBlocksUpdate(t *testing.T) {
+	t.Log("Starting TestLockRecordBlocksUpdate: Verifying a locked record refuses updates")
+	ctx, stub, _ := newMockContext("Org2MSP")
+
+	stub.On("GetState", "REC001").Return(mustMarshal(t, VerificationRecord{ID: "REC001", Description: "Initial", Party: "Org2MSP", Status: "CREATED", Version: 1}), nil).Twice()
+	stub.On("GetTxTimestamp").Return(timestamppb.Now(), nil)
+	var written VerificationRecord
+	writes := 0
+	stub.On("PutState", "REC001", mock.Anything).Run(func(args mock.Arguments) {
+		writes++
+		_ = json.Unmarshal(args.Get(1).([]byte), &written)
+	}).Return(nil)
+	stub.On("SetEvent", "RecordLocked", mock.Anything).Return(nil)
+
+	contract := new(HistoryContract)
+	err := contract.LockRecord(ctx, "REC001")
+	assert.NoError(t, err)
+	assert.True(t, written.Locked)
+	stub.AssertCalled(t, "SetEvent", "RecordLocked", mock.Anything)
+
+	t.Log("Updating the locked record...")
+	stub.On("GetState", "REC001").Return(mustMarshal(t, written), nil)
+	err = contract.UpdateRecord(ctx, "REC001", "Changed", "PENDING", "", written.Version)
+
+	assert.EqualError(t, err, "record REC001 is locked and must be unlocked before it can change")
+	assert.Equal(t, 1, writes)
+	t.Log("The update was refused with the lock error rather than the seal error")
+}
+
+func TestUnlockRecordRestoresUpdate(t *testing.T) {
+	t.Log("Starting TestUnlockRecordRestoresUpdate: Verifying updates work again once the admin unlocks")
+	ctx, stub, _ := newMockContext(AdminMSPID)
+
+	locked := VerificationRecord{ID: "REC001", Description: "Initial", Party: "Org2MSP", Status: "CREATED", Version: 2, Locked: true}
+	stub.On("GetState", "REC001").Return(mustMarshal(t, locked), nil).Twice()
+	stub.On("GetTxTimestamp").Return(timestamppb.Now(), nil)
+	var written VerificationRecord
+	stub.On("PutState", "REC001", mock.Anything).Run(func(args mock.Arguments) {
+		_ = json.Unmarshal(args.Get(1).([]byte), &written)
+	}).Return(nil)
+	stub.On("PutState", mock.Anything, indexEntryValue).Return(nil)
+	stub.On("DelState", mock.Anything).Return(nil)
+	stub.On("SetEvent", mock.Anything, mock.Anything).Return(nil)
+	stub.On("GetState", compositeKey(configObjectType, configWorkflowGraph)).Return(nil, nil)
+
+	contract := new(HistoryContract)
+	err := contract.UnlockRecord(ctx, "REC001")
+	assert.NoError(t, err)
+	assert.False(t, written.Locked)
+	stub.AssertCalled(t, "SetEvent", "RecordUnlocked", mock.Anything)
+
+	stub.On("GetState", "REC001").Return(mustMarshal(t, written), nil)
+	err = contract.UnlockRecord(ctx, "REC001")
+	assert.EqualError(t, err, "record REC001 is already unlocked")
+
+	t.Log("Updating the unlocked record...")
+	err = contract.UpdateRecord(ctx, "REC001", "Changed", "PENDING", "", written.Version)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "PENDING", written.Status)
+	t.Log("The update went through after the lock was lifted")
+}
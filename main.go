@@ -1,8 +1,11 @@
 package main
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/hyperledger/fabric-contract-api-go/contractapi"
@@ -20,9 +23,69 @@ type VerificationRecord struct {
 	Party       string `json:"party"`     // The organization/user currently acting
 	Status      string `json:"status"`    // e.g., "CREATED", "VERIFIED", "REJECTED"
 	Timestamp   string `json:"timestamp"` // Application level timestamp
+	CreatedAt   string `json:"createdAt"` // Timestamp of the creating transaction, kept across updates
+	// Confidential records only reveal their Description to the owning party and the admin MSP
+	Confidential bool `json:"confidential"`
+	// RequiredApprovers lists the MSP IDs that must sign off on the record; Approvals holds those that have
+	RequiredApprovers []string `json:"requiredApprovers,omitempty" metadata:",optional"`
+	Approvals         []string `json:"approvals,omitempty" metadata:",optional"`
+	// ProposedStatus is a pending two-phase status change made by ProposedBy, awaiting CommitStatusChange
+	ProposedStatus string `json:"proposedStatus,omitempty" metadata:",optional"`
+	ProposedBy     string `json:"proposedBy,omitempty" metadata:",optional"`
+	// Metadata holds free-form organization specific attributes (e.g., Location, BatchID)
+	Metadata map[string]string `json:"metadata,omitempty" metadata:",optional"`
+	// BatchID links a record back to the BatchImport call that created it
+	BatchID string `json:"batchId"`
+	// Sealed records are final and must not be modified or archived
+	Sealed bool `json:"sealed"`
+	// Locked records are frozen, e.g. during a dispute, until the owner or admin unlocks them again.
+	// Unlike Sealed the lock is temporary.
+	Locked bool `json:"locked,omitempty" metadata:",optional"`
+	// Deleted marks a soft-deleted record; it stays in world state but is hidden from normal listings
+	Deleted bool `json:"deleted"`
+	// ParentID links a derived record (e.g. a shipment manifest) to the record it derives from
+	ParentID string `json:"parentId,omitempty" metadata:",optional"`
+	// RejectionReason explains why a record was rejected; it is cleared once the record leaves REJECTED
+	RejectionReason string `json:"rejectionReason,omitempty" metadata:",optional"`
+	// Version counts the writes to the record, starting at 1; UpdateRecord uses it for optimistic locking
+	Version int `json:"version"`
+	// Hash is the SHA-256 of the record's canonical content (excluding Hash itself), refreshed on every write
+	Hash string `json:"hash,omitempty" metadata:",optional"`
+	// PrevHash is the content hash of the version this one replaced, chaining the record's history
+	PrevHash string `json:"prevHash,omitempty" metadata:",optional"`
+	// DocumentHash is the SHA-256 of an external document (PDF, manifest) kept off-chain
+	DocumentHash string `json:"documentHash,omitempty" metadata:",optional"`
+	// Quantity is the number of units the record covers; it must not be negative
+	Quantity int64 `json:"quantity,omitempty" metadata:",optional"`
+	// Category separates record types sharing the ledger (see AllowedCategories); empty means uncategorized
+	Category string `json:"category,omitempty" metadata:",optional"`
+	// SchemaVersion is the layout the record was last written in; records from before versioning have none
+	// and count as version 1. MigrateRecord brings older records up to currentSchemaVersion.
+	SchemaVersion int `json:"schemaVersion,omitempty" metadata:",optional"`
 	// You can add more fields here to match organization requirements (e.g., Location, BatchID)
 }
 
+// AdminMSPID is the organization allowed to read and manage every record regardless of ownership
+const AdminMSPID = "Org1MSP"
+
+// Certificate attribute a caller must hold to create records, checked on top of the MSP ID
+const (
+	CreatorAttribute      = "role"
+	CreatorAttributeValue = "auditor"
+)
+
+// redactedValue replaces confidential fields for callers that are not allowed to see them
+const redactedValue = "[REDACTED]"
+
+// StatusChangeEvent is the payload of the RecordUpdated chaincode event
+type StatusChangeEvent struct {
+	ID        string `json:"id"`
+	OldStatus string `json:"oldStatus"`
+	NewStatus string `json:"newStatus"`
+	Party     string `json:"party"`
+	Timestamp string `json:"timestamp"`
+}
+
 // HistoryQueryResult structure used for returning history data
 type HistoryQueryResult struct {
 	TxId      string              `json:"txId"`
@@ -31,7 +94,9 @@ type HistoryQueryResult struct {
 	Record    *VerificationRecord `json:"record"`
 }
 
-// InitLedger adds a base set of records to the ledger
+// InitLedger adds a base set of records to the ledger and builds their indexes.
+// Records that already exist are left untouched, but their index entries are still written
+// from the stored state so re-running InitLedger never leaves the indexes half-built.
 func (s *HistoryContract) InitLedger(ctx contractapi.TransactionContextInterface) error {
 	// Use Transaction Timestamp for determinism, not time.Now()
 	txTimestamp, err := ctx.GetStub().GetTxTimestamp()
@@ -41,106 +106,326 @@ func (s *HistoryContract) InitLedger(ctx contractapi.TransactionContextInterface
 	timestampStr := time.Unix(txTimestamp.Seconds, int64(txTimestamp.Nanos)).Format(time.RFC3339)
 
 	records := []VerificationRecord{
-		{ID: "REC001", Description: "Initial Contract Draft", Party: "Org1", Status: "CREATED", Timestamp: timestampStr},
-		{ID: "REC002", Description: "Shipping Manifest", Party: "Org2", Status: "PENDING", Timestamp: timestampStr},
+		{ID: "REC001", Description: "Initial Contract Draft", Party: "Org1", Status: StatusCreated, Timestamp: timestampStr, Category: CategoryContract},
+		{ID: "REC002", Description: "Shipping Manifest", Party: "Org2", Status: StatusPending, Timestamp: timestampStr, Category: CategoryShipment},
 	}
 
-	for _, record := range records {
-		assetJSON, err := json.Marshal(record)
+	for i := range records {
+		record := &records[i]
+
+		existingJSON, err := ctx.GetStub().GetState(record.ID)
 		if err != nil {
-			return err
+			return fmt.Errorf("failed to read from world state: %v", err)
+		}
+		if existingJSON == nil {
+			// putRecord indexes the new record as it writes it
+			if err := s.putRecord(ctx, record); err != nil {
+				return err
+			}
+			continue
 		}
 
-		err = ctx.GetStub().PutState(record.ID, assetJSON)
-		if err != nil {
-			return fmt.Errorf("failed to put to world state. %v", err)
+		// Index what is actually stored rather than the seed values
+		if err := json.Unmarshal(existingJSON, record); err != nil {
+			return fmt.Errorf("failed to unmarshal record %s: %v", record.ID, err)
+		}
+		if err := indexRecord(ctx, record); err != nil {
+			return err
 		}
 	}
 
 	return nil
 }
 
+// Batch import modes
+const (
+	BatchModeAbort = "abort" // Fail the whole batch on the first problem; nothing is written
+	BatchModeSkip  = "skip"  // Skip existing IDs and report invalid records, importing everything else
+)
+
+// maxBatchSize bounds a single BatchImport so the transaction stays within message size and timeout limits
+const maxBatchSize = 500
+
+// BatchImportReport lists the outcome of every record in a batch
+type BatchImportReport struct {
+	Imported []string          `json:"imported"`
+	Skipped  []string          `json:"skipped"`
+	Failed   map[string]string `json:"failed"`
+}
+
 // BatchImport allows uploading multiple records at once.
 // This is the correct way to import data fetched from an external API:
 // 1. The Client App (off-chain) fetches the data from the API.
 // 2. The Client App calls this function passing the data as a JSON string.
-func (s *HistoryContract) BatchImport(ctx contractapi.TransactionContextInterface, data string) error {
+// When batchID is not empty every imported record is stamped with it so the import can be traced later.
+// mode is BatchModeAbort (the default when empty) or BatchModeSkip; in skip mode existing IDs are reported
+// as skipped and invalid records as failed while the rest of the batch is still written.
+func (s *HistoryContract) BatchImport(ctx contractapi.TransactionContextInterface, data string, batchID string, mode string) (*BatchImportReport, error) {
 	var records []VerificationRecord
 	if err := json.Unmarshal([]byte(data), &records); err != nil {
-		return fmt.Errorf("failed to unmarshal data: %v", err)
+		return nil, fmt.Errorf("failed to unmarshal data: %v", err)
 	}
 
+	return s.importRecords(ctx, records, batchID, mode, false)
+}
+
+// ValidateBatch is a dry run of BatchImport in skip mode: the caller and every record go through the same
+// authorization, validation and existence checks, but nothing is written. Imported lists the IDs that would be imported, Skipped those that
+// already exist and Failed those that are invalid. It is meant to be evaluated on a peer, not submitted.
+func (s *HistoryContract) ValidateBatch(ctx contractapi.TransactionContextInterface, data string) (*BatchImportReport, error) {
+	var records []VerificationRecord
+	if err := json.Unmarshal([]byte(data), &records); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal data: %v", err)
+	}
+
+	return s.importRecords(ctx, records, "", BatchModeSkip, true)
+}
+
+// importRecords writes decoded batch records under the given mode; it backs both the JSON and CSV imports.
+// With dryRun set the records are checked and reported as usual but never written.
+// Fields managed by the contract itself (see clearManagedFields) are dropped from every imported record.
+func (s *HistoryContract) importRecords(ctx contractapi.TransactionContextInterface, records []VerificationRecord, batchID string, mode string, dryRun bool) (*BatchImportReport, error) {
+	if mode == "" {
+		mode = BatchModeAbort
+	}
+	if mode != BatchModeAbort && mode != BatchModeSkip {
+		return nil, fmt.Errorf("unknown batch import mode %q, expected %s or %s", mode, BatchModeAbort, BatchModeSkip)
+	}
+
+	if len(records) > maxBatchSize {
+		return nil, fmt.Errorf("batch size %d exceeds maximum %d", len(records), maxBatchSize)
+	}
+
+	// A repeated ID would otherwise surface as a misleading "already exists" on its second occurrence
+	seen := make(map[string]bool, len(records))
 	for _, record := range records {
-		assetJSON, err := json.Marshal(record)
-		if err != nil {
-			return err
+		if seen[record.ID] {
+			return nil, fmt.Errorf("duplicate ID %s in batch", record.ID)
+		}
+		seen[record.ID] = true
+	}
+
+	graph, err := getWorkflowGraph(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Imports create records, so the caller must be allowed to create them. A dry run checks this as well,
+	// otherwise it would report records as importable that the real import then refuses.
+	if err := requireCreatorAttribute(ctx); err != nil {
+		return nil, err
+	}
+	clientIdentity, err := ctx.GetClientIdentity().GetMSPID()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get client identity: %v", err)
+	}
+	if err := s.requireAuthorizedCreator(ctx, clientIdentity); err != nil {
+		return nil, err
+	}
+
+	report := &BatchImportReport{Imported: []string{}, Skipped: []string{}, Failed: map[string]string{}}
+	for _, record := range records {
+		clearManagedFields(&record)
+		if err := validateRecord(&record, graph); err != nil {
+			if mode == BatchModeAbort {
+				return nil, err
+			}
+			report.Failed[record.ID] = err.Error()
+			continue
+		}
+
+		if batchID != "" {
+			record.BatchID = batchID
 		}
 
 		// Production Safety: Check if record exists to prevent accidental data loss
 		exists, err := s.RecordExists(ctx, record.ID)
 		if err != nil {
-			return err
+			return nil, err
 		}
 		if exists {
-			return fmt.Errorf("record %s already exists - batch import aborted", record.ID)
+			if mode == BatchModeAbort {
+				return nil, fmt.Errorf("record %s already exists - batch import aborted: %w", record.ID, ErrRecordExists)
+			}
+			report.Skipped = append(report.Skipped, record.ID)
+			continue
 		}
 
-		if err := ctx.GetStub().PutState(record.ID, assetJSON); err != nil {
-			return fmt.Errorf("failed to put to world state for ID %s: %v", record.ID, err)
+		if !dryRun {
+			if err := s.putRecord(ctx, &record); err != nil {
+				return nil, err
+			}
 		}
+		report.Imported = append(report.Imported, record.ID)
 	}
-	return nil
+	return report, nil
 }
 
-// CreateRecord issues a new record to the world state
-func (s *HistoryContract) CreateRecord(ctx contractapi.TransactionContextInterface, id string, description string, status string) error {
-	exists, err := s.RecordExists(ctx, id)
+// clearManagedFields resets the fields only the contract's own transactions may set, so imported data
+// cannot carry forged proposals, sign-offs, approver lists, locks or deletion state
+func clearManagedFields(record *VerificationRecord) {
+	record.ProposedStatus = ""
+	record.ProposedBy = ""
+	record.Approvals = nil
+	record.RequiredApprovers = nil
+	record.Sealed = false
+	record.Locked = false
+	record.Deleted = false
+	record.RejectionReason = ""
+}
+
+// BatchImportWithChecksum imports data only if its SHA-256 hex digest matches expectedChecksum,
+// guaranteeing a large payload was not corrupted between the off-chain client and the peer.
+func (s *HistoryContract) BatchImportWithChecksum(ctx contractapi.TransactionContextInterface, data string, expectedChecksum string) error {
+	sum := sha256.Sum256([]byte(data))
+	computed := hex.EncodeToString(sum[:])
+	if !strings.EqualFold(computed, expectedChecksum) {
+		return fmt.Errorf("checksum mismatch: expected %s but computed %s - batch import aborted", expectedChecksum, computed)
+	}
+
+	_, err := s.BatchImport(ctx, data, "", BatchModeAbort)
+	return err
+}
+
+// CreateRecord issues a new record to the world state.
+// metadataJSON is an optional JSON object of string values, e.g. {"location":"Nairobi"}; pass "" for none.
+// category must be one of AllowedCategories, or "" to leave the record uncategorized.
+func (s *HistoryContract) CreateRecord(ctx contractapi.TransactionContextInterface, id string, description string, status string, metadataJSON string, quantity int64, category string) error {
+	metadata, err := parseMetadata(metadataJSON)
 	if err != nil {
 		return err
 	}
+
+	_, err = s.createRecord(ctx, id, description, status, metadata, quantity, category)
+	return err
+}
+
+// CreateOrGetRecord creates a record like CreateRecord, but returns the existing record instead of failing
+// when the ID is already taken, so clients with at-least-once delivery can simply retry.
+// The existing record is returned as stored (masked as in GetRecord), even if its fields differ from the arguments.
+func (s *HistoryContract) CreateOrGetRecord(ctx contractapi.TransactionContextInterface, id string, description string, status string) (*VerificationRecord, error) {
+	exists, err := s.RecordExists(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return s.GetRecord(ctx, id)
+	}
+
+	return s.createRecord(ctx, id, description, status, nil, 0, "")
+}
+
+// createRecord writes a new record owned by the caller and returns it
+func (s *HistoryContract) createRecord(ctx contractapi.TransactionContextInterface, id string, description string, status string, metadata map[string]string, quantity int64, category string) (*VerificationRecord, error) {
+	graph, err := getWorkflowGraph(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateRecord(&VerificationRecord{ID: id, Description: description, Status: status, Quantity: quantity, Category: category}, graph); err != nil {
+		return nil, err
+	}
+
+	if err := requireCreatorAttribute(ctx); err != nil {
+		return nil, err
+	}
+
+	exists, err := s.RecordExists(ctx, id)
+	if err != nil {
+		return nil, err
+	}
 	if exists {
-		return fmt.Errorf("the record %s already exists", id)
+		return nil, fmt.Errorf("the record %s already exists: %w", id, ErrRecordExists)
 	}
 
 	// Get the identity of the submitter (the Party)
 	clientIdentity, err := ctx.GetClientIdentity().GetMSPID()
 	if err != nil {
-		return fmt.Errorf("failed to get client identity: %v", err)
+		return nil, fmt.Errorf("failed to get client identity: %v", err)
+	}
+	if err := s.requireAuthorizedCreator(ctx, clientIdentity); err != nil {
+		return nil, err
 	}
 
 	// Use Transaction Timestamp
 	txTimestamp, err := ctx.GetStub().GetTxTimestamp()
 	if err != nil {
-		return err
+		return nil, err
 	}
 
+	timestampStr := time.Unix(txTimestamp.Seconds, int64(txTimestamp.Nanos)).Format(time.RFC3339)
 	record := VerificationRecord{
 		ID:          id,
 		Description: description,
 		Party:       clientIdentity,
 		Status:      status,
-		Timestamp:   time.Unix(txTimestamp.Seconds, int64(txTimestamp.Nanos)).Format(time.RFC3339),
+		Timestamp:   timestampStr,
+		CreatedAt:   timestampStr,
+		Metadata:    metadata,
+		Quantity:    quantity,
+		Category:    category,
+	}
+
+	if err := s.putRecord(ctx, &record); err != nil {
+		return nil, err
 	}
 
 	recordJSON, err := json.Marshal(record)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	return ctx.GetStub().PutState(id, recordJSON)
+	// Let off-chain listeners react to the new record without polling
+	if err := ctx.GetStub().SetEvent("RecordCreated", recordJSON); err != nil {
+		return nil, fmt.Errorf("failed to emit RecordCreated event for %s: %v", id, err)
+	}
+	return &record, nil
 }
 
-// UpdateRecord allows a party to update the status or description, creating a new history entry
-func (s *HistoryContract) UpdateRecord(ctx contractapi.TransactionContextInterface, id string, description string, status string) error {
-	exists, err := s.RecordExists(ctx, id)
+// UpdateRecord allows a party to update the status or description, creating a new history entry.
+// A non-empty metadataJSON replaces the record's metadata; "" keeps it as it is.
+func (s *HistoryContract) UpdateRecord(ctx contractapi.TransactionContextInterface, id string, description string, status string, metadataJSON string, expectedVersion int) error {
+	metadata, err := parseMetadata(metadataJSON)
 	if err != nil {
 		return err
 	}
-	if !exists {
-		return fmt.Errorf("the record %s does not exist", id)
+
+	// Read the current state so flags such as Confidential survive the update
+	updatedRecord, err := s.readRecord(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err := ensureMutable(updatedRecord); err != nil {
+		return err
+	}
+
+	graph, err := getWorkflowGraph(ctx)
+	if err != nil {
+		return err
+	}
+	if err := validateRecord(&VerificationRecord{ID: id, Description: description, Status: status}, graph); err != nil {
+		return err
+	}
+
+	// The caller must have seen the latest version, otherwise it would silently overwrite someone else's write
+	if updatedRecord.Version != expectedVersion {
+		return fmt.Errorf("version conflict on record %s: expected version %d but found %d", id, expectedVersion, updatedRecord.Version)
 	}
 
-	clientIdentity, _ := ctx.GetClientIdentity().GetMSPID()
+	// Parties must not tamper with each other's entries; only the owner or the admin MSP may update.
+	// The record stays with its owner, so an admin override does not take it over.
+	if _, err := authorizeOwner(ctx, updatedRecord, "update"); err != nil {
+		return err
+	}
+
+	// Only moves allowed by the workflow may be written, e.g. no jumping from CREATED straight to VERIFIED
+	allowed, err := isValidTransition(ctx, updatedRecord.Status, status)
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		return fmt.Errorf("invalid status transition from %s to %s: %w", updatedRecord.Status, status, ErrInvalidStatus)
+	}
 
 	// Use Transaction Timestamp
 	txTimestamp, err := ctx.GetStub().GetTxTimestamp()
@@ -149,26 +434,128 @@ func (s *HistoryContract) UpdateRecord(ctx contractapi.TransactionContextInterfa
 	}
 
 	// Overwrite the record. Fabric automatically keeps the old version in the history.
-	updatedRecord := VerificationRecord{
-		ID:          id,
-		Description: description,
-		Party:       clientIdentity,
-		Status:      status,
-		Timestamp:   time.Unix(txTimestamp.Seconds, int64(txTimestamp.Nanos)).Format(time.RFC3339),
+	oldStatus := updatedRecord.Status
+	updatedRecord.Description = description
+	updatedRecord.Status = status
+	updatedRecord.Timestamp = time.Unix(txTimestamp.Seconds, int64(txTimestamp.Nanos)).Format(time.RFC3339)
+	if metadataJSON != "" {
+		updatedRecord.Metadata = metadata
+	}
+
+	if err := s.putRecord(ctx, updatedRecord); err != nil {
+		return err
+	}
+
+	return emitStatusChange(ctx, oldStatus, updatedRecord)
+}
+
+// UpdateRecordIf applies UpdateRecord only while the record still has expectedStatus, e.g. "update only if
+// still PENDING". This gives optimistic concurrency for workflows where parties race on the same record.
+func (s *HistoryContract) UpdateRecordIf(ctx contractapi.TransactionContextInterface, id string, expectedStatus string, description string, newStatus string) error {
+	if err := requireKnownStatus(ctx, expectedStatus); err != nil {
+		return err
+	}
+
+	current, err := s.readRecord(ctx, id)
+	if err != nil {
+		return err
+	}
+	if current.Status != expectedStatus {
+		return fmt.Errorf("precondition failed: expected status %s but found %s", expectedStatus, current.Status)
+	}
+
+	return s.UpdateRecord(ctx, id, description, newStatus, "", current.Version)
+}
+
+// SetRecordConfidential marks a record as confidential (or public again).
+// Only the owning party or the admin MSP may change the flag.
+func (s *HistoryContract) SetRecordConfidential(ctx contractapi.TransactionContextInterface, id string, confidential bool) error {
+	record, err := s.readRecord(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if _, err := authorizeOwner(ctx, record, "change confidentiality of"); err != nil {
+		return err
 	}
 
-	recordJSON, err := json.Marshal(updatedRecord)
+	timestamp, err := txTimestampString(ctx)
 	if err != nil {
 		return err
 	}
 
-	return ctx.GetStub().PutState(id, recordJSON)
+	record.Confidential = confidential
+	record.Timestamp = timestamp
+	return s.putRecord(ctx, record)
+}
+
+// GetRecord returns the current state of a single record without walking its history.
+// Confidential records have their Description masked unless the caller owns the record or is the admin MSP.
+func (s *HistoryContract) GetRecord(ctx contractapi.TransactionContextInterface, id string) (*VerificationRecord, error) {
+	record, err := s.readRecord(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := redactConfidential(ctx, record); err != nil {
+		return nil, err
+	}
+
+	return record, nil
+}
+
+// GetLatestStatus returns only the current status of a record, for cheap polling
+func (s *HistoryContract) GetLatestStatus(ctx contractapi.TransactionContextInterface, id string) (string, error) {
+	record, err := s.readRecord(ctx, id)
+	if err != nil {
+		return "", err
+	}
+	return record.Status, nil
+}
+
+// DeleteRecord removes a record from the world state.
+// Fabric keeps the deletion in the key's history (IsDelete=true), so GetRecordHistory still shows it.
+// Only the owning party or the admin MSP may delete a record.
+func (s *HistoryContract) DeleteRecord(ctx contractapi.TransactionContextInterface, id string) error {
+	exists, err := s.RecordExists(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return fmt.Errorf("the record %s does not exist: %w", id, ErrRecordNotFound)
+	}
+
+	record, err := s.readRecord(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := ensureMutable(record); err != nil {
+		return err
+	}
+	if _, err := authorizeOwner(ctx, record, "delete"); err != nil {
+		return err
+	}
+	return s.removeRecord(ctx, record)
 }
 
 // GetRecordHistory returns the chain of custody/history for a specific record
 // This is the core function for your verification use case.
+// Confidential descriptions are masked in every version as in GetRecord.
 func (s *HistoryContract) GetRecordHistory(ctx contractapi.TransactionContextInterface, id string) ([]HistoryQueryResult, error) {
+	history, err := s.readRecordHistory(ctx, id)
+	if err != nil {
+		return nil, err
+	}
 
+	if err := redactHistory(ctx, history); err != nil {
+		return nil, err
+	}
+	return history, nil
+}
+
+// readRecordHistory returns every history entry of a record without masking, for internal checks
+// such as hash verification that need the stored content
+func (s *HistoryContract) readRecordHistory(ctx contractapi.TransactionContextInterface, id string) ([]HistoryQueryResult, error) {
 	// GetHistoryForKey is a Fabric API that retrieves all state changes for a key
 	resultsIterator, err := ctx.GetStub().GetHistoryForKey(id)
 	if err != nil {
@@ -206,6 +593,41 @@ func (s *HistoryContract) GetRecordHistory(ctx contractapi.TransactionContextInt
 	return records, nil
 }
 
+// maxLedgerQueryAttempts bounds how often QueryOtherLedger invokes the other chaincode
+const maxLedgerQueryAttempts = 3
+
+// transientLedgerQueryStatuses are the response statuses worth retrying; anything else fails at once
+var transientLedgerQueryStatuses = map[int32]bool{
+	429: true, // Too many requests
+	503: true, // Service unavailable
+	504: true, // Gateway timeout
+}
+
+// minInvokeBudget is the time that must be left before a context deadline to start another cross-channel invoke
+const minInvokeBudget = 2 * time.Second
+
+// deadlineContext is implemented by transaction contexts that carry a deadline, mirroring context.Context
+type deadlineContext interface {
+	Deadline() (deadline time.Time, ok bool)
+}
+
+// ensureInvokeBudget refuses to start a cross-channel invoke when the context's deadline has passed or is
+// closer than minInvokeBudget. Contexts without a deadline are never refused.
+func ensureInvokeBudget(ctx contractapi.TransactionContextInterface, channelName string) error {
+	withDeadline, ok := ctx.(deadlineContext)
+	if !ok {
+		return nil
+	}
+	deadline, ok := withDeadline.Deadline()
+	if !ok {
+		return nil
+	}
+	if remaining := time.Until(deadline); remaining < minInvokeBudget {
+		return fmt.Errorf("not enough time left to query channel %s: deadline %s is less than %s away", channelName, deadline.UTC().Format(time.RFC3339), minInvokeBudget)
+	}
+	return nil
+}
+
 // QueryOtherLedger allows this contract to read data from a different channel's ledger.
 // Note: Cross-channel invocations are READ-ONLY. You cannot write to the other ledger.
 // This uses Fabric's internal gRPC protocol, not HTTP.
@@ -214,15 +636,30 @@ func (s *HistoryContract) QueryOtherLedger(ctx contractapi.TransactionContextInt
 	// The first argument is the function name to call on the target chaincode
 	chainCodeArgs := [][]byte{[]byte(functionName), []byte(arg)}
 
-	// InvokeChaincode calls the specified chaincode on the specified channel
-	response := ctx.GetStub().InvokeChaincode(chaincodeName, chainCodeArgs, channelName)
+	// InvokeChaincode calls the specified chaincode on the specified channel.
+	// Transient failures are retried immediately: sleeping would make endorsement timing-dependent,
+	// so the backoff is simply the bounded number of attempts.
+	// The deadline is only checked before each attempt: Fabric cannot interrupt an invoke already in flight,
+	// so a target that hangs still holds the transaction until the peer's own execute timeout.
+	var lastErr error
+	for attempt := 1; attempt <= maxLedgerQueryAttempts; attempt++ {
+		if err := ensureInvokeBudget(ctx, channelName); err != nil {
+			return "", err
+		}
+		response := ctx.GetStub().InvokeChaincode(chaincodeName, chainCodeArgs, channelName)
+
+		// Check if the response status is OK (200)
+		if response.Status == 200 {
+			return string(response.Payload), nil
+		}
 
-	// Check if the response status is OK (200)
-	if response.Status != 200 {
-		return "", fmt.Errorf("failed to query other ledger. Message: %s", response.Message)
+		lastErr = fmt.Errorf("failed to query other ledger. Message: %s", response.Message)
+		if !transientLedgerQueryStatuses[response.Status] {
+			break
+		}
 	}
 
-	return string(response.Payload), nil
+	return "", lastErr
 }
 
 // RecordExists returns true when asset with given ID exists in world state
@@ -235,6 +672,219 @@ func (s *HistoryContract) RecordExists(ctx contractapi.TransactionContextInterfa
 	return recordJSON != nil, nil
 }
 
+// readRecord loads a record from world state without applying any caller-based masking
+func (s *HistoryContract) readRecord(ctx contractapi.TransactionContextInterface, id string) (*VerificationRecord, error) {
+	recordJSON, err := ctx.GetStub().GetState(id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read from world state: %v", err)
+	}
+	if recordJSON == nil {
+		return nil, fmt.Errorf("record %s does not exist: %w", id, ErrRecordNotFound)
+	}
+
+	var record VerificationRecord
+	if err := json.Unmarshal(recordJSON, &record); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal record %s: %v", id, err)
+	}
+
+	return &record, nil
+}
+
+// putRecord writes a record to world state under its ID, bumping its version, stamping its integrity hash
+// and keeping the secondary indexes in step.
+// The previously committed version is read back so only the index entries that changed are rewritten.
+func (s *HistoryContract) putRecord(ctx contractapi.TransactionContextInterface, record *VerificationRecord) error {
+	previousJSON, err := ctx.GetStub().GetState(record.ID)
+	if err != nil {
+		return fmt.Errorf("failed to read from world state: %v", err)
+	}
+	var previous *VerificationRecord
+	if previousJSON != nil {
+		if err := json.Unmarshal(previousJSON, &previous); err != nil {
+			return fmt.Errorf("failed to unmarshal record %s: %v", record.ID, err)
+		}
+		// Last line of defense for write paths that do not check the seal or lock themselves;
+		// a locked record may only be replaced by the write that lifts the lock
+		if err := ensureNotSealed(previous); err != nil {
+			return err
+		}
+		if previous.Locked && record.Locked {
+			return ensureMutable(previous)
+		}
+	}
+
+	// New records are written in the current schema straight away
+	if previous == nil {
+		migrateRecordSchema(record)
+	}
+
+	record.Version = 1
+	record.PrevHash = ""
+	if previous != nil {
+		record.Version = previous.Version + 1
+		prevHash, err := computeRecordHash(previous)
+		if err != nil {
+			return err
+		}
+		record.PrevHash = prevHash
+	}
+
+	// A rejection reason only describes the REJECTED state, whichever path moved the record out of it
+	if record.Status != StatusRejected {
+		record.RejectionReason = ""
+	}
+	// A pending proposal was made against the previous status, so any status change makes it stale
+	if previous != nil && previous.Status != record.Status {
+		record.ProposedStatus = ""
+		record.ProposedBy = ""
+	}
+
+	hash, err := computeRecordHash(record)
+	if err != nil {
+		return err
+	}
+	record.Hash = hash
+
+	recordJSON, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	if err := ctx.GetStub().PutState(record.ID, recordJSON); err != nil {
+		return fmt.Errorf("failed to put to world state for ID %s: %v", record.ID, err)
+	}
+	return reindexRecord(ctx, previous, record)
+}
+
+// removeRecord deletes a record from world state together with its index entries
+func (s *HistoryContract) removeRecord(ctx contractapi.TransactionContextInterface, record *VerificationRecord) error {
+	if err := ensureMutable(record); err != nil {
+		return err
+	}
+	if err := ctx.GetStub().DelState(record.ID); err != nil {
+		return fmt.Errorf("failed to delete record %s: %v", record.ID, err)
+	}
+	return unindexRecord(ctx, record)
+}
+
+// getAllRecords scans the full world state and returns every record without masking
+func (s *HistoryContract) getAllRecords(ctx contractapi.TransactionContextInterface) ([]*VerificationRecord, error) {
+	resultsIterator, err := ctx.GetStub().GetStateByRange("", "")
+	if err != nil {
+		return nil, err
+	}
+	defer resultsIterator.Close()
+
+	var records []*VerificationRecord
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+
+		var record VerificationRecord
+		if err := json.Unmarshal(queryResponse.Value, &record); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal record %s: %v", queryResponse.Key, err)
+		}
+		records = append(records, &record)
+	}
+
+	return records, nil
+}
+
+// authorizeOwner returns the caller's MSP ID if it owns the record or is the admin MSP.
+// The action is used in the error message, e.g. "update".
+func authorizeOwner(ctx contractapi.TransactionContextInterface, record *VerificationRecord, action string) (string, error) {
+	clientIdentity, err := ctx.GetClientIdentity().GetMSPID()
+	if err != nil {
+		return "", fmt.Errorf("failed to get client identity: %v", err)
+	}
+	if clientIdentity != record.Party && !isAdmin(clientIdentity) {
+		return "", fmt.Errorf("caller %s is not authorized to %s record owned by %s: %w", clientIdentity, action, record.Party, ErrUnauthorized)
+	}
+	return clientIdentity, nil
+}
+
+// emitStatusChange fires the RecordUpdated event for a record that was just written.
+// Subscribers get the transition directly instead of diffing state themselves.
+func emitStatusChange(ctx contractapi.TransactionContextInterface, oldStatus string, record *VerificationRecord) error {
+	eventJSON, err := json.Marshal(StatusChangeEvent{
+		ID:        record.ID,
+		OldStatus: oldStatus,
+		NewStatus: record.Status,
+		Party:     record.Party,
+		Timestamp: record.Timestamp,
+	})
+	if err != nil {
+		return err
+	}
+	if err := ctx.GetStub().SetEvent("RecordUpdated", eventJSON); err != nil {
+		return fmt.Errorf("failed to emit RecordUpdated event for %s: %v", record.ID, err)
+	}
+	return nil
+}
+
+// txTimestampString returns the transaction timestamp formatted as RFC3339.
+// The transaction timestamp is used instead of time.Now() so every endorser computes the same value.
+func txTimestampString(ctx contractapi.TransactionContextInterface) (string, error) {
+	txTimestamp, err := ctx.GetStub().GetTxTimestamp()
+	if err != nil {
+		return "", err
+	}
+	return time.Unix(txTimestamp.Seconds, int64(txTimestamp.Nanos)).Format(time.RFC3339), nil
+}
+
+// requireCreatorAttribute rejects callers whose certificate lacks CreatorAttribute=CreatorAttributeValue
+func requireCreatorAttribute(ctx contractapi.TransactionContextInterface) error {
+	value, found, err := ctx.GetClientIdentity().GetAttributeValue(CreatorAttribute)
+	if err != nil {
+		return fmt.Errorf("failed to read attribute %s: %v", CreatorAttribute, err)
+	}
+	if !found || value != CreatorAttributeValue {
+		return fmt.Errorf("caller must hold attribute %s=%s to create records: %w", CreatorAttribute, CreatorAttributeValue, ErrUnauthorized)
+	}
+	return nil
+}
+
+// isAdmin reports whether the given MSP ID is the admin organization
+func isAdmin(mspID string) bool {
+	return mspID == AdminMSPID
+}
+
+// redactConfidential masks the Description of confidential records the caller does not own.
+// The caller identity is only resolved when at least one record is confidential.
+func redactConfidential(ctx contractapi.TransactionContextInterface, records ...*VerificationRecord) error {
+	clientIdentity := ""
+	for _, record := range records {
+		if record == nil || !record.Confidential {
+			continue
+		}
+
+		if clientIdentity == "" {
+			mspID, err := ctx.GetClientIdentity().GetMSPID()
+			if err != nil {
+				return fmt.Errorf("failed to get client identity: %v", err)
+			}
+			clientIdentity = mspID
+		}
+
+		if clientIdentity != record.Party && !isAdmin(clientIdentity) {
+			record.Description = redactedValue
+		}
+	}
+	return nil
+}
+
+// redactHistory masks confidential descriptions in every version of a history, as redactConfidential does
+// for current records. Each version is judged by the party that owned it at the time.
+func redactHistory(ctx contractapi.TransactionContextInterface, history []HistoryQueryResult) error {
+	records := make([]*VerificationRecord, 0, len(history))
+	for _, entry := range history {
+		records = append(records, entry.Record)
+	}
+	return redactConfidential(ctx, records...)
+}
+
 func main() {
 	chaincode, err := contractapi.NewChaincode(&HistoryContract{})
 	if err != nil {
@@ -1,13 +1,19 @@
 package main
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"fmt"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/hyperledger/fabric-chaincode-go/pkg/cid"
 	"github.com/hyperledger/fabric-chaincode-go/shim"
 	"github.com/hyperledger/fabric-contract-api-go/contractapi"
 	"github.com/hyperledger/fabric-protos-go/ledger/queryresult"
+	"github.com/hyperledger/fabric-protos-go/peer"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"google.golang.org/protobuf/types/known/timestamppb"
@@ -63,6 +69,72 @@ func (m *MockChaincodeStub) GetTxTimestamp() (*timestamppb.Timestamp, error) {
 	return args.Get(0).(*timestamppb.Timestamp), args.Error(1)
 }
 
+func (m *MockChaincodeStub) GetStateByRange(startKey string, endKey string) (shim.StateQueryIteratorInterface, error) {
+	args := m.Called(startKey, endKey)
+	return args.Get(0).(shim.StateQueryIteratorInterface), args.Error(1)
+}
+
+func (m *MockChaincodeStub) GetQueryResult(query string) (shim.StateQueryIteratorInterface, error) {
+	args := m.Called(query)
+	return args.Get(0).(shim.StateQueryIteratorInterface), args.Error(1)
+}
+
+func (m *MockChaincodeStub) SetEvent(name string, payload []byte) error {
+	args := m.Called(name, payload)
+	return args.Error(0)
+}
+
+func (m *MockChaincodeStub) InvokeChaincode(chaincodeName string, args [][]byte, channel string) peer.Response {
+	return m.Called(chaincodeName, args, channel).Get(0).(peer.Response)
+}
+
+func (m *MockChaincodeStub) GetTransient() (map[string][]byte, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string][]byte), args.Error(1)
+}
+
+func (m *MockChaincodeStub) PutPrivateData(collection string, key string, value []byte) error {
+	args := m.Called(collection, key, value)
+	return args.Error(0)
+}
+
+func (m *MockChaincodeStub) GetPrivateData(collection string, key string) ([]byte, error) {
+	args := m.Called(collection, key)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]byte), args.Error(1)
+}
+
+func (m *MockChaincodeStub) DelState(key string) error {
+	args := m.Called(key)
+	return args.Error(0)
+}
+
+func (m *MockChaincodeStub) GetStateByPartialCompositeKey(objectType string, keys []string) (shim.StateQueryIteratorInterface, error) {
+	args := m.Called(objectType, keys)
+	return args.Get(0).(shim.StateQueryIteratorInterface), args.Error(1)
+}
+
+func (m *MockChaincodeStub) GetStateByRangeWithPagination(startKey string, endKey string, pageSize int32, bookmark string) (shim.StateQueryIteratorInterface, *peer.QueryResponseMetadata, error) {
+	args := m.Called(startKey, endKey, pageSize, bookmark)
+	return args.Get(0).(shim.StateQueryIteratorInterface), args.Get(1).(*peer.QueryResponseMetadata), args.Error(2)
+}
+
+// CreateCompositeKey mirrors the shim's key format so tests can predict index keys
+func (m *MockChaincodeStub) CreateCompositeKey(objectType string, attributes []string) (string, error) {
+	return compositeKey(objectType, attributes...), nil
+}
+
+// SplitCompositeKey reverses CreateCompositeKey using the shim's key format
+func (m *MockChaincodeStub) SplitCompositeKey(compositeKey string) (string, []string, error) {
+	parts := strings.Split(strings.Trim(compositeKey, "\x00"), "\x00")
+	return parts[0], parts[1:], nil
+}
+
 // MockClientIdentity mocks the client identity (MSP ID)
 type MockClientIdentity struct {
 	cid.ClientIdentity
@@ -74,6 +146,11 @@ func (m *MockClientIdentity) GetMSPID() (string, error) {
 	return args.String(0), args.Error(1)
 }
 
+func (m *MockClientIdentity) GetAttributeValue(attrName string) (string, bool, error) {
+	args := m.Called(attrName)
+	return args.String(0), args.Bool(1), args.Error(2)
+}
+
 // MockHistoryQueryIterator mocks the iterator for history results
 type MockHistoryQueryIterator struct {
 	shim.HistoryQueryIteratorInterface
@@ -98,6 +175,110 @@ func (m *MockHistoryQueryIterator) Close() error {
 	return args.Error(0)
 }
 
+// MockStateQueryIterator mocks the iterator for range and rich query results
+type MockStateQueryIterator struct {
+	shim.StateQueryIteratorInterface
+	mock.Mock
+}
+
+func (m *MockStateQueryIterator) HasNext() bool {
+	args := m.Called()
+	return args.Bool(0)
+}
+
+func (m *MockStateQueryIterator) Next() (*queryresult.KV, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*queryresult.KV), args.Error(1)
+}
+
+func (m *MockStateQueryIterator) Close() error {
+	args := m.Called()
+	return args.Error(0)
+}
+
+// --- Helpers ---
+
+// newMockContext wires a transaction context to a fresh stub and a client identity acting as mspID
+func newMockContext(mspID string) (*MockTransactionContext, *MockChaincodeStub, *MockClientIdentity) {
+	ctx := new(MockTransactionContext)
+	stub := new(MockChaincodeStub)
+	clientIdentity := new(MockClientIdentity)
+
+	ctx.On("GetStub").Return(stub)
+	ctx.On("GetClientIdentity").Return(clientIdentity)
+	clientIdentity.On("GetMSPID").Return(mspID, nil)
+
+	return ctx, stub, clientIdentity
+}
+
+// newStateIterator returns a mock iterator yielding the given records in order
+func newStateIterator(t *testing.T, records ...VerificationRecord) *MockStateQueryIterator {
+	kvs := make([]*queryresult.KV, 0, len(records))
+	for _, record := range records {
+		kvs = append(kvs, &queryresult.KV{Key: record.ID, Value: mustMarshal(t, record)})
+	}
+	return newKVIterator(kvs...)
+}
+
+// newKVIterator returns a mock iterator yielding raw key/value pairs in order
+func newKVIterator(kvs ...*queryresult.KV) *MockStateQueryIterator {
+	iterator := new(MockStateQueryIterator)
+	for _, kv := range kvs {
+		iterator.On("HasNext").Return(true).Once()
+		iterator.On("Next").Return(kv, nil).Once()
+	}
+	iterator.On("HasNext").Return(false)
+	iterator.On("Close").Return(nil)
+	return iterator
+}
+
+// newHistoryIterator returns a mock history iterator yielding the given modifications in order
+func newHistoryIterator(modifications ...*queryresult.KeyModification) *MockHistoryQueryIterator {
+	iterator := new(MockHistoryQueryIterator)
+	for _, modification := range modifications {
+		iterator.On("HasNext").Return(true).Once()
+		iterator.On("Next").Return(modification, nil).Once()
+	}
+	iterator.On("HasNext").Return(false)
+	iterator.On("Close").Return(nil)
+	return iterator
+}
+
+// historyEntry builds a history modification for record at the given time; a nil record is a delete marker
+func historyEntry(t *testing.T, txID string, timestamp time.Time, record *VerificationRecord) *queryresult.KeyModification {
+	modification := &queryresult.KeyModification{
+		TxId:      txID,
+		Timestamp: timestamppb.New(timestamp),
+		IsDelete:  record == nil,
+	}
+	if record != nil {
+		modification.Value = mustMarshal(t, record)
+	}
+	return modification
+}
+
+// compositeKey builds the same key as shim's CreateCompositeKey
+func compositeKey(objectType string, attributes ...string) string {
+	key := "\x00" + objectType + "\x00"
+	for _, attribute := range attributes {
+		key += attribute + "\x00"
+	}
+	return key
+}
+
+// mustMarshal encodes v as JSON, failing the test on error
+func mustMarshal(t *testing.T, v interface{}) []byte {
+	t.Helper()
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("failed to marshal test fixture: %v", err)
+	}
+	return data
+}
+
 // --- Tests ---
 
 func TestCreateRecord(t *testing.T) {
@@ -108,23 +289,201 @@ func TestCreateRecord(t *testing.T) {
 
 	ctx.On("GetStub").Return(stub)
 	ctx.On("GetClientIdentity").Return(clientIdentity)
+	stub.On("GetState", compositeKey(configObjectType, configWorkflowGraph)).Return(nil, nil)
+	stub.On("GetState", compositeKey(configObjectType, configAuthorizedMSPs)).Return(nil, nil)
 
 	t.Log("Setting expectations: Checking if record exists and putting new state")
 	// Expectation: Record does not exist yet
 	stub.On("GetState", "REC001").Return(nil, nil)
 	clientIdentity.On("GetMSPID").Return("Org1MSP", nil)
+	clientIdentity.On("GetAttributeValue", CreatorAttribute).Return(CreatorAttributeValue, true, nil)
 	stub.On("GetTxTimestamp").Return(timestamppb.Now(), nil)
 	stub.On("PutState", "REC001", mock.Anything).Return(nil)
+	stub.On("PutState", compositeKey(partyIndex, "Org1MSP", "REC001"), indexEntryValue).Return(nil)
+	stub.On("PutState", compositeKey(statusIndex, "CREATED", "REC001"), indexEntryValue).Return(nil)
+	stub.On("SetEvent", "RecordCreated", mock.Anything).Return(nil)
 
 	t.Log("Invoking CreateRecord smart contract function...")
 	contract := new(HistoryContract)
-	err := contract.CreateRecord(ctx, "REC001", "Initial Draft", "CREATED")
+	err := contract.CreateRecord(ctx, "REC001", "Initial Draft", "CREATED", "", 0, "")
 
 	assert.NoError(t, err)
 	t.Log("CreateRecord returned no error")
 	stub.AssertExpectations(t)
 }
 
+func TestCreateRecordEmitsEvent(t *testing.T) {
+	t.Log("Starting TestCreateRecordEmitsEvent: Verifying a RecordCreated event carries the new record")
+	ctx, stub, clientIdentity := newMockContext("Org1MSP")
+	stub.On("GetState", compositeKey(configObjectType, configWorkflowGraph)).Return(nil, nil)
+	stub.On("GetState", compositeKey(configObjectType, configAuthorizedMSPs)).Return(nil, nil)
+	clientIdentity.On("GetAttributeValue", CreatorAttribute).Return(CreatorAttributeValue, true, nil)
+
+	stub.On("GetState", "REC001").Return(nil, nil)
+	stub.On("GetTxTimestamp").Return(timestamppb.Now(), nil)
+	stub.On("PutState", "REC001", mock.Anything).Return(nil)
+	stub.On("PutState", compositeKey(partyIndex, "Org1MSP", "REC001"), indexEntryValue).Return(nil)
+	stub.On("PutState", compositeKey(statusIndex, "CREATED", "REC001"), indexEntryValue).Return(nil)
+	stub.On("SetEvent", "RecordCreated", mock.Anything).Return(nil)
+
+	contract := new(HistoryContract)
+	err := contract.CreateRecord(ctx, "REC001", "Initial Draft", "CREATED", "", 0, "")
+	assert.NoError(t, err)
+
+	stub.AssertCalled(t, "SetEvent", "RecordCreated", mock.Anything)
+	var payload []byte
+	for _, call := range stub.Calls {
+		if call.Method == "SetEvent" {
+			payload = call.Arguments.Get(1).([]byte)
+		}
+	}
+
+	var emitted VerificationRecord
+	assert.NoError(t, json.Unmarshal(payload, &emitted))
+	assert.Equal(t, "REC001", emitted.ID)
+	assert.Equal(t, "Initial Draft", emitted.Description)
+	assert.Equal(t, "Org1MSP", emitted.Party)
+	assert.Equal(t, "CREATED", emitted.Status)
+	assert.Equal(t, 1, emitted.Version)
+	t.Log("Event payload unmarshals back to the created record")
+}
+
+func TestUpdateRecordEmitsStatusChangeEvent(t *testing.T) {
+	t.Log("Starting TestUpdateRecordEmitsStatusChangeEvent: Verifying RecordUpdated carries the old and new status")
+	ctx, stub, _ := newMockContext("Org2MSP")
+
+	existing := &VerificationRecord{ID: "REC001", Description: "Initial", Party: "Org2MSP", Status: "PENDING", Version: 1}
+	stub.On("GetState", "REC001").Return(mustMarshal(t, existing), nil)
+	stub.On("GetState", compositeKey(configObjectType, configWorkflowGraph)).Return(nil, nil)
+	stub.On("GetTxTimestamp").Return(timestamppb.New(time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)), nil)
+	stub.On("PutState", "REC001", mock.Anything).Return(nil)
+	stub.On("PutState", mock.Anything, indexEntryValue).Return(nil)
+	stub.On("DelState", mock.Anything).Return(nil)
+	stub.On("SetEvent", "RecordUpdated", mock.Anything).Return(nil)
+
+	contract := new(HistoryContract)
+	err := contract.UpdateRecord(ctx, "REC001", "Inspected", "VERIFIED", "", 1)
+	assert.NoError(t, err)
+
+	var payload []byte
+	for _, call := range stub.Calls {
+		if call.Method == "SetEvent" {
+			payload = call.Arguments.Get(1).([]byte)
+		}
+	}
+
+	var event StatusChangeEvent
+	assert.NoError(t, json.Unmarshal(payload, &event))
+	assert.Equal(t, StatusChangeEvent{
+		ID:        "REC001",
+		OldStatus: "PENDING",
+		NewStatus: "VERIFIED",
+		Party:     "Org2MSP",
+		Timestamp: time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC).Local().Format(time.RFC3339),
+	}, event)
+	t.Log("Event reported the PENDING -> VERIFIED transition")
+}
+
+func TestUpdateRecordByOwner(t *testing.T) {
+	t.Log("Starting TestUpdateRecordByOwner: Verifying the owning party can update its record")
+	ctx, stub, _ := newMockContext("Org2MSP")
+
+	existing := &VerificationRecord{ID: "REC001", Description: "Initial", Party: "Org2MSP", Status: "CREATED", Version: 1}
+	stub.On("GetState", "REC001").Return(mustMarshal(t, existing), nil)
+	stub.On("GetState", compositeKey(configObjectType, configWorkflowGraph)).Return(nil, nil)
+	stub.On("GetTxTimestamp").Return(timestamppb.Now(), nil)
+	stub.On("PutState", "REC001", mock.Anything).Return(nil)
+	stub.On("PutState", mock.Anything, indexEntryValue).Return(nil)
+	stub.On("DelState", mock.Anything).Return(nil)
+	stub.On("SetEvent", "RecordUpdated", mock.Anything).Return(nil)
+
+	contract := new(HistoryContract)
+	err := contract.UpdateRecord(ctx, "REC001", "Submitted", "PENDING", "", 1)
+
+	assert.NoError(t, err)
+	stub.AssertCalled(t, "PutState", "REC001", mock.Anything)
+}
+
+func TestUpdateRecordByOtherPartyRejected(t *testing.T) {
+	t.Log("Starting TestUpdateRecordByOtherPartyRejected: Verifying a non-owner cannot update a record")
+	ctx, stub, _ := newMockContext("Org3MSP")
+	stub.On("GetState", compositeKey(configObjectType, configWorkflowGraph)).Return(nil, nil)
+
+	existing := &VerificationRecord{ID: "REC001", Description: "Initial", Party: "Org2MSP", Status: "CREATED", Version: 1}
+	stub.On("GetState", "REC001").Return(mustMarshal(t, existing), nil)
+
+	contract := new(HistoryContract)
+	err := contract.UpdateRecord(ctx, "REC001", "Tampered", "PENDING", "", 1)
+
+	assert.EqualError(t, err, "caller Org3MSP is not authorized to update record owned by Org2MSP: unauthorized")
+	stub.AssertNotCalled(t, "PutState", mock.Anything, mock.Anything)
+	t.Log("Update by a foreign party was rejected without writing")
+}
+
+func TestUpdateRecordAdminOverride(t *testing.T) {
+	t.Log("Starting TestUpdateRecordAdminOverride: Verifying the admin MSP can update any record")
+	ctx, stub, _ := newMockContext(AdminMSPID)
+
+	existing := &VerificationRecord{ID: "REC001", Description: "Initial", Party: "Org2MSP", Status: "CREATED", Version: 1}
+	stub.On("GetState", "REC001").Return(mustMarshal(t, existing), nil)
+	stub.On("GetState", compositeKey(configObjectType, configWorkflowGraph)).Return(nil, nil)
+	stub.On("GetTxTimestamp").Return(timestamppb.Now(), nil)
+	var written VerificationRecord
+	stub.On("PutState", "REC001", mock.Anything).Run(func(args mock.Arguments) {
+		_ = json.Unmarshal(args.Get(1).([]byte), &written)
+	}).Return(nil)
+	stub.On("PutState", mock.Anything, indexEntryValue).Return(nil)
+	stub.On("DelState", mock.Anything).Return(nil)
+	stub.On("SetEvent", "RecordUpdated", mock.Anything).Return(nil)
+
+	contract := new(HistoryContract)
+	err := contract.UpdateRecord(ctx, "REC001", "Corrected by admin", "PENDING", "", 1)
+
+	assert.NoError(t, err)
+	stub.AssertCalled(t, "PutState", "REC001", mock.Anything)
+	assert.Equal(t, "Corrected by admin", written.Description)
+	assert.Equal(t, "Org2MSP", written.Party, "the admin override must not take ownership")
+}
+
+func TestUpdateRecordVersioned(t *testing.T) {
+	t.Log("Starting TestUpdateRecordVersioned: Verifying an update at the current version bumps it")
+	ctx, stub, _ := newMockContext("Org2MSP")
+
+	existing := &VerificationRecord{ID: "REC001", Description: "Initial", Party: "Org2MSP", Status: "CREATED", Version: 3}
+	stub.On("GetState", "REC001").Return(mustMarshal(t, existing), nil)
+	stub.On("GetState", compositeKey(configObjectType, configWorkflowGraph)).Return(nil, nil)
+	stub.On("GetTxTimestamp").Return(timestamppb.Now(), nil)
+	var written VerificationRecord
+	stub.On("PutState", "REC001", mock.Anything).Run(func(args mock.Arguments) {
+		_ = json.Unmarshal(args.Get(1).([]byte), &written)
+	}).Return(nil)
+	stub.On("PutState", mock.Anything, indexEntryValue).Return(nil)
+	stub.On("DelState", mock.Anything).Return(nil)
+	stub.On("SetEvent", "RecordUpdated", mock.Anything).Return(nil)
+
+	contract := new(HistoryContract)
+	err := contract.UpdateRecord(ctx, "REC001", "Submitted", "PENDING", "", 3)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 4, written.Version)
+}
+
+func TestUpdateRecordStaleVersion(t *testing.T) {
+	t.Log("Starting TestUpdateRecordStaleVersion: Verifying an update based on an old version is rejected")
+	ctx, stub, _ := newMockContext("Org2MSP")
+	stub.On("GetState", compositeKey(configObjectType, configWorkflowGraph)).Return(nil, nil)
+
+	existing := &VerificationRecord{ID: "REC001", Description: "Edited elsewhere", Party: "Org2MSP", Status: "PENDING", Version: 4}
+	stub.On("GetState", "REC001").Return(mustMarshal(t, existing), nil)
+
+	contract := new(HistoryContract)
+	err := contract.UpdateRecord(ctx, "REC001", "Submitted", "PENDING", "", 3)
+
+	assert.EqualError(t, err, "version conflict on record REC001: expected version 3 but found 4")
+	stub.AssertNotCalled(t, "PutState", mock.Anything, mock.Anything)
+	t.Log("The stale write was refused")
+}
+
 func TestGetRecordHistory(t *testing.T) {
 	t.Log("Starting TestGetRecordHistory: Verifying history retrieval")
 	ctx := new(MockTransactionContext)
@@ -162,6 +521,272 @@ func TestGetRecordHistory(t *testing.T) {
 	t.Log("Verification of history record content passed")
 }
 
+func TestInitLedgerBuildsIndexes(t *testing.T) {
+	t.Log("Starting TestInitLedgerBuildsIndexes: Verifying seeded records are indexed by party and status")
+	ctx, stub, _ := newMockContext("Org1MSP")
+
+	stub.On("GetTxTimestamp").Return(timestamppb.Now(), nil)
+	stub.On("GetState", "REC001").Return(nil, nil)
+	// REC002 already exists from a previous run with a different status
+	stub.On("GetState", "REC002").Return(mustMarshal(t, VerificationRecord{ID: "REC002", Party: "Org2", Status: "VERIFIED"}), nil)
+	stub.On("PutState", mock.Anything, mock.Anything).Return(nil)
+
+	t.Log("Invoking InitLedger...")
+	contract := new(HistoryContract)
+	err := contract.InitLedger(ctx)
+
+	assert.NoError(t, err)
+	stub.AssertCalled(t, "PutState", "REC001", mock.MatchedBy(func(value []byte) bool {
+		var record VerificationRecord
+		return json.Unmarshal(value, &record) == nil && record.Category == CategoryContract
+	}))
+	stub.AssertNotCalled(t, "PutState", "REC002", mock.Anything)
+	stub.AssertCalled(t, "PutState", compositeKey(partyIndex, "Org1", "REC001"), indexEntryValue)
+	stub.AssertCalled(t, "PutState", compositeKey(partyIndex, "Org2", "REC002"), indexEntryValue)
+	stub.AssertCalled(t, "PutState", compositeKey(statusIndex, "VERIFIED", "REC002"), indexEntryValue)
+	t.Log("Party and status index entries were written for every seeded record")
+}
+
+func TestDeleteRecord(t *testing.T) {
+	t.Log("Starting TestDeleteRecord: Verifying deletion and that history keeps the delete marker")
+	ctx, stub, _ := newMockContext("Org1MSP")
+
+	record := VerificationRecord{ID: "REC001", Description: "Draft", Status: "CREATED"}
+	stub.On("GetState", "REC001").Return(mustMarshal(t, record), nil)
+	stub.On("DelState", "REC001").Return(nil)
+	stub.On("DelState", compositeKey(statusIndex, "CREATED", "REC001")).Return(nil)
+
+	t.Log("Invoking DeleteRecord...")
+	contract := new(HistoryContract)
+	err := contract.DeleteRecord(ctx, "REC001")
+
+	assert.NoError(t, err)
+	stub.AssertExpectations(t)
+
+	t.Log("Reading the history after deletion...")
+	created := time.Date(2024, 3, 1, 8, 0, 0, 0, time.UTC)
+	stub.On("GetHistoryForKey", "REC001").Return(newHistoryIterator(
+		historyEntry(t, "tx1", created, &record),
+		historyEntry(t, "tx2", created.Add(time.Hour), nil),
+	), nil)
+
+	history, err := contract.GetRecordHistory(ctx, "REC001")
+
+	assert.NoError(t, err)
+	assert.Len(t, history, 2)
+	assert.True(t, history[1].IsDelete)
+	assert.Nil(t, history[1].Record)
+	t.Log("The deletion marker is still surfaced by GetRecordHistory")
+}
+
+func TestDeleteRecordMissing(t *testing.T) {
+	t.Log("Starting TestDeleteRecordMissing: Verifying a missing record cannot be deleted")
+	ctx, stub, _ := newMockContext("Org1MSP")
+	stub.On("GetState", "REC404").Return(nil, nil)
+
+	contract := new(HistoryContract)
+	err := contract.DeleteRecord(ctx, "REC404")
+
+	assert.EqualError(t, err, "the record REC404 does not exist: record not found")
+	stub.AssertNotCalled(t, "DelState", mock.Anything)
+}
+
+func TestDeleteRecordRejectsNonOwner(t *testing.T) {
+	t.Log("Starting TestDeleteRecordRejectsNonOwner: Verifying another organization cannot delete the record")
+	ctx, stub, _ := newMockContext("Org3MSP")
+	stub.On("GetState", "REC001").Return(mustMarshal(t, VerificationRecord{ID: "REC001", Party: "Org2MSP", Status: "CREATED"}), nil)
+
+	contract := new(HistoryContract)
+	err := contract.DeleteRecord(ctx, "REC001")
+
+	assert.ErrorIs(t, err, ErrUnauthorized)
+	stub.AssertNotCalled(t, "DelState", mock.Anything)
+}
+
+func TestBatchImportWithChecksumMismatch(t *testing.T) {
+	t.Log("Starting TestBatchImportWithChecksumMismatch: Verifying a corrupted payload is not imported")
+	ctx, stub, _ := newMockContext("Org1MSP")
+
+	data := `[{"id":"IMP001","description":"Imported record","status":"CREATED"}]`
+	sum := sha256.Sum256([]byte(data))
+	actual := hex.EncodeToString(sum[:])
+	wrong := strings.Repeat("0", 64)
+
+	contract := new(HistoryContract)
+	err := contract.BatchImportWithChecksum(ctx, data, wrong)
+
+	assert.EqualError(t, err, "checksum mismatch: expected "+wrong+" but computed "+actual+" - batch import aborted")
+	stub.AssertNotCalled(t, "PutState", mock.Anything, mock.Anything)
+	t.Log("The import was aborted and both checksums were reported")
+}
+
+func TestBatchImportWithChecksumMatch(t *testing.T) {
+	ctx, stub, clientIdentity := newMockContext("Org1MSP")
+	clientIdentity.On("GetAttributeValue", CreatorAttribute).Return(CreatorAttributeValue, true, nil)
+	stub.On("GetState", compositeKey(configObjectType, configWorkflowGraph)).Return(nil, nil)
+	stub.On("GetState", compositeKey(configObjectType, configAuthorizedMSPs)).Return(nil, nil)
+	stub.On("GetState", "IMP001").Return(nil, nil)
+	stub.On("PutState", "IMP001", mock.Anything).Return(nil)
+	stub.On("PutState", compositeKey(statusIndex, "CREATED", "IMP001"), indexEntryValue).Return(nil)
+
+	data := `[{"id":"IMP001","description":"Imported record","status":"CREATED"}]`
+	sum := sha256.Sum256([]byte(data))
+
+	contract := new(HistoryContract)
+	err := contract.BatchImportWithChecksum(ctx, data, strings.ToUpper(hex.EncodeToString(sum[:])))
+
+	assert.NoError(t, err)
+	stub.AssertExpectations(t)
+}
+
+func TestBatchImportAbortModeStopsOnConflict(t *testing.T) {
+	t.Log("Starting TestBatchImportAbortModeStopsOnConflict: Verifying abort mode fails the batch on an existing ID")
+	ctx, stub, clientIdentity := newMockContext("Org1MSP")
+	clientIdentity.On("GetAttributeValue", CreatorAttribute).Return(CreatorAttributeValue, true, nil)
+	stub.On("GetState", compositeKey(configObjectType, configWorkflowGraph)).Return(nil, nil)
+	stub.On("GetState", compositeKey(configObjectType, configAuthorizedMSPs)).Return(nil, nil)
+	stub.On("GetState", "IMP001").Return(nil, nil)
+	stub.On("GetState", "IMP002").Return(mustMarshal(t, VerificationRecord{ID: "IMP002"}), nil)
+	stub.On("PutState", mock.Anything, mock.Anything).Return(nil)
+
+	contract := new(HistoryContract)
+	data := `[{"id":"IMP001","description":"Imported record","status":"CREATED"},{"id":"IMP002","description":"Imported record","status":"CREATED"},{"id":"IMP003","description":"Imported record","status":"CREATED"}]`
+	report, err := contract.BatchImport(ctx, data, "", BatchModeAbort)
+
+	assert.EqualError(t, err, "record IMP002 already exists - batch import aborted: record already exists")
+	assert.Nil(t, report)
+	stub.AssertNotCalled(t, "GetState", "IMP003")
+	t.Log("The batch was aborted at the conflicting ID")
+}
+
+func TestBatchImportSkipModeReportsConflicts(t *testing.T) {
+	t.Log("Starting TestBatchImportSkipModeReportsConflicts: Verifying skip mode imports around conflicts")
+	ctx, stub, clientIdentity := newMockContext("Org1MSP")
+	clientIdentity.On("GetAttributeValue", CreatorAttribute).Return(CreatorAttributeValue, true, nil)
+	stub.On("GetState", compositeKey(configObjectType, configWorkflowGraph)).Return(nil, nil)
+	stub.On("GetState", compositeKey(configObjectType, configAuthorizedMSPs)).Return(nil, nil)
+	stub.On("GetState", "IMP001").Return(nil, nil)
+	stub.On("GetState", "IMP002").Return(mustMarshal(t, VerificationRecord{ID: "IMP002"}), nil)
+	stub.On("GetState", "IMP003").Return(nil, nil)
+	stub.On("PutState", mock.Anything, mock.Anything).Return(nil)
+
+	contract := new(HistoryContract)
+	data := `[{"id":"IMP001","description":"Imported record","status":"CREATED"},{"id":"IMP002","description":"Imported record","status":"CREATED"},{"id":"IMP\u200b004"},{"id":"IMP003","description":"Imported record","status":"CREATED"}]`
+	report, err := contract.BatchImport(ctx, data, "", BatchModeSkip)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"IMP001", "IMP003"}, report.Imported)
+	assert.Equal(t, []string{"IMP002"}, report.Skipped)
+	assert.Equal(t, map[string]string{"IMP\u200b004": `record ID "IMP\u200b004" contains disallowed character U+200B`}, report.Failed)
+	stub.AssertCalled(t, "PutState", "IMP001", mock.Anything)
+	stub.AssertCalled(t, "PutState", "IMP003", mock.Anything)
+	stub.AssertNotCalled(t, "PutState", "IMP002", mock.Anything)
+	t.Log("New IDs were written, the existing one skipped and the invalid one reported")
+}
+
+func TestBatchImportDropsManagedFields(t *testing.T) {
+	t.Log("Starting TestBatchImportDropsManagedFields: Verifying an imported proposal cannot be committed")
+	ctx, stub, clientIdentity := newMockContext("Org2MSP")
+	clientIdentity.On("GetAttributeValue", CreatorAttribute).Return(CreatorAttributeValue, true, nil)
+	stub.On("GetState", compositeKey(configObjectType, configWorkflowGraph)).Return(nil, nil)
+	stub.On("GetState", compositeKey(configObjectType, configAuthorizedMSPs)).Return(nil, nil)
+	stub.On("GetState", "IMP001").Return(nil, nil)
+	var written VerificationRecord
+	stub.On("PutState", "IMP001", mock.Anything).Run(func(args mock.Arguments) {
+		_ = json.Unmarshal(args.Get(1).([]byte), &written)
+	}).Return(nil)
+	stub.On("PutState", mock.Anything, indexEntryValue).Return(nil)
+
+	contract := new(HistoryContract)
+	data := `[{"id":"IMP001","description":"Imported record","party":"Org2MSP","status":"PENDING","proposedStatus":"VERIFIED","proposedBy":"Org9MSP",
+		"requiredApprovers":["Org3MSP"],"sealed":true,"locked":true,"deleted":true,"rejectionReason":"forged"}]`
+	_, err := contract.BatchImport(ctx, data, "", BatchModeAbort)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "PENDING", written.Status)
+	assert.Empty(t, written.ProposedStatus)
+	assert.Empty(t, written.ProposedBy)
+	assert.Empty(t, written.RequiredApprovers)
+	assert.False(t, written.Sealed)
+	assert.False(t, written.Locked)
+	assert.False(t, written.Deleted)
+	assert.Empty(t, written.RejectionReason)
+
+	t.Log("Committing the forged proposal as the importing organization...")
+	commitCtx, commitStub, _ := newMockContext("Org2MSP")
+	commitStub.On("GetState", "IMP001").Return(mustMarshal(t, written), nil)
+	err = contract.CommitStatusChange(commitCtx, "IMP001")
+
+	assert.EqualError(t, err, "record IMP001 has no pending status change to commit")
+	commitStub.AssertNotCalled(t, "PutState", mock.Anything, mock.Anything)
+	t.Log("The proposal was dropped on import, so there was nothing to commit")
+}
+
+func TestValidateBatchWritesNothing(t *testing.T) {
+	t.Log("Starting TestValidateBatchWritesNothing: Verifying the dry run reports outcomes without writing")
+	ctx, stub, clientIdentity := newMockContext("Org1MSP")
+	clientIdentity.On("GetAttributeValue", CreatorAttribute).Return(CreatorAttributeValue, true, nil)
+	stub.On("GetState", compositeKey(configObjectType, configWorkflowGraph)).Return(nil, nil)
+	stub.On("GetState", compositeKey(configObjectType, configAuthorizedMSPs)).Return(nil, nil)
+	stub.On("GetState", "IMP001").Return(nil, nil)
+	stub.On("GetState", "IMP002").Return(mustMarshal(t, VerificationRecord{ID: "IMP002"}), nil)
+
+	contract := new(HistoryContract)
+	data := `[{"id":"IMP001","description":"Imported record","status":"CREATED"},{"id":"IMP002","description":"Imported record","status":"CREATED"},{"id":"IMP003","description":"Imported record","status":"SHIPPED"}]`
+	report, err := contract.ValidateBatch(ctx, data)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"IMP001"}, report.Imported)
+	assert.Equal(t, []string{"IMP002"}, report.Skipped)
+	assert.Equal(t, map[string]string{"IMP003": `unknown status "SHIPPED" for record IMP003: invalid status`}, report.Failed)
+	stub.AssertNotCalled(t, "PutState", mock.Anything, mock.Anything)
+
+	t.Log("Validating the same batch as callers the real import would refuse...")
+	ctx, stub, clientIdentity = newMockContext("Org1MSP")
+	clientIdentity.On("GetAttributeValue", CreatorAttribute).Return("operator", true, nil)
+	stub.On("GetState", compositeKey(configObjectType, configWorkflowGraph)).Return(nil, nil)
+	_, err = contract.ValidateBatch(ctx, data)
+	assert.EqualError(t, err, "caller must hold attribute role=auditor to create records: unauthorized")
+
+	ctx, stub, clientIdentity = newMockContext("Org3MSP")
+	clientIdentity.On("GetAttributeValue", CreatorAttribute).Return(CreatorAttributeValue, true, nil)
+	stub.On("GetState", compositeKey(configObjectType, configWorkflowGraph)).Return(nil, nil)
+	stub.On("GetState", compositeKey(configObjectType, configAuthorizedMSPs)).Return([]byte(`["Org1MSP","Org2MSP"]`), nil)
+	_, err = contract.ValidateBatch(ctx, data)
+	assert.EqualError(t, err, "organization Org3MSP is not authorized to create records: unauthorized")
+	t.Log("Each record was classified, nothing was written and unauthorized callers were refused")
+}
+
+func TestBatchImportRejectsOversizedBatch(t *testing.T) {
+	t.Log("Starting TestBatchImportRejectsOversizedBatch: Verifying an oversized batch is refused before any write")
+	ctx, stub, _ := newMockContext("Org1MSP")
+
+	records := make([]VerificationRecord, maxBatchSize+1)
+	for i := range records {
+		records[i] = VerificationRecord{ID: fmt.Sprintf("IMP%04d", i), Status: "CREATED"}
+	}
+
+	contract := new(HistoryContract)
+	_, err := contract.BatchImport(ctx, string(mustMarshal(t, records)), "", BatchModeSkip)
+
+	assert.EqualError(t, err, "batch size 501 exceeds maximum 500")
+	stub.AssertNotCalled(t, "GetState", mock.Anything)
+	stub.AssertNotCalled(t, "PutState", mock.Anything, mock.Anything)
+	t.Log("No record of the oversized batch was written")
+}
+
+func TestBatchImportRejectsDuplicateIDs(t *testing.T) {
+	t.Log("Starting TestBatchImportRejectsDuplicateIDs: Verifying a repeated ID is reported before any write")
+	ctx, stub, _ := newMockContext("Org1MSP")
+
+	contract := new(HistoryContract)
+	data := `[{"id":"IMP001","description":"Imported record","status":"CREATED"},{"id":"IMP002","description":"Imported record","status":"CREATED"},{"id":"IMP001","description":"Imported record","status":"PENDING"}]`
+	_, err := contract.BatchImport(ctx, data, "", BatchModeAbort)
+
+	assert.EqualError(t, err, "duplicate ID IMP001 in batch")
+	stub.AssertNotCalled(t, "PutState", mock.Anything, mock.Anything)
+}
+
 func TestCreateRecordOrg3(t *testing.T) {
 	t.Log("Starting TestCreateRecordOrg3: Verifying creation for Org3")
 	ctx := new(MockTransactionContext)
@@ -170,17 +795,23 @@ func TestCreateRecordOrg3(t *testing.T) {
 
 	ctx.On("GetStub").Return(stub)
 	ctx.On("GetClientIdentity").Return(clientIdentity)
+	stub.On("GetState", compositeKey(configObjectType, configWorkflowGraph)).Return(nil, nil)
+	stub.On("GetState", compositeKey(configObjectType, configAuthorizedMSPs)).Return(nil, nil)
 
 	t.Log("Setting expectations: Checking if record exists and putting new state for Org3")
 	// Expectation: Record does not exist yet
 	stub.On("GetState", "REC003").Return(nil, nil)
 	clientIdentity.On("GetMSPID").Return("Org3MSP", nil)
+	clientIdentity.On("GetAttributeValue", CreatorAttribute).Return(CreatorAttributeValue, true, nil)
 	stub.On("GetTxTimestamp").Return(timestamppb.Now(), nil)
 	stub.On("PutState", "REC003", mock.Anything).Return(nil)
+	stub.On("PutState", compositeKey(partyIndex, "Org3MSP", "REC003"), indexEntryValue).Return(nil)
+	stub.On("PutState", compositeKey(statusIndex, "CREATED", "REC003"), indexEntryValue).Return(nil)
+	stub.On("SetEvent", "RecordCreated", mock.Anything).Return(nil)
 
 	t.Log("Invoking CreateRecord smart contract function as Org3...")
 	contract := new(HistoryContract)
-	err := contract.CreateRecord(ctx, "REC003", "Org3 Draft", "CREATED")
+	err := contract.CreateRecord(ctx, "REC003", "Org3 Draft", "CREATED", "", 0, "")
 
 	assert.NoError(t, err)
 	t.Log("CreateRecord returned no error for Org3")
@@ -195,19 +826,272 @@ func TestCreateRecordOrg4(t *testing.T) {
 
 	ctx.On("GetStub").Return(stub)
 	ctx.On("GetClientIdentity").Return(clientIdentity)
+	stub.On("GetState", compositeKey(configObjectType, configWorkflowGraph)).Return(nil, nil)
+	stub.On("GetState", compositeKey(configObjectType, configAuthorizedMSPs)).Return(nil, nil)
 
 	t.Log("Setting expectations: Checking if record exists and putting new state for Org4")
 	// Expectation: Record does not exist yet
 	stub.On("GetState", "REC004").Return(nil, nil)
 	clientIdentity.On("GetMSPID").Return("Org4MSP", nil)
+	clientIdentity.On("GetAttributeValue", CreatorAttribute).Return(CreatorAttributeValue, true, nil)
 	stub.On("GetTxTimestamp").Return(timestamppb.Now(), nil)
 	stub.On("PutState", "REC004", mock.Anything).Return(nil)
+	stub.On("PutState", compositeKey(partyIndex, "Org4MSP", "REC004"), indexEntryValue).Return(nil)
+	stub.On("PutState", compositeKey(statusIndex, "CREATED", "REC004"), indexEntryValue).Return(nil)
+	stub.On("SetEvent", "RecordCreated", mock.Anything).Return(nil)
 
 	t.Log("Invoking CreateRecord smart contract function as Org4...")
 	contract := new(HistoryContract)
-	err := contract.CreateRecord(ctx, "REC004", "Org4 Draft", "CREATED")
+	err := contract.CreateRecord(ctx, "REC004", "Org4 Draft", "CREATED", "", 0, "")
 
 	assert.NoError(t, err)
 	t.Log("CreateRecord returned no error for Org4")
 	stub.AssertExpectations(t)
 }
+
+func TestGetRecord(t *testing.T) {
+	t.Log("Starting TestGetRecord: Verifying a single record is read by ID")
+	ctx, stub, _ := newMockContext("Org1MSP")
+
+	record := VerificationRecord{ID: "REC001", Description: "Initial Contract Draft", Party: "Org1MSP", Status: "CREATED"}
+	stub.On("GetState", "REC001").Return(mustMarshal(t, record), nil)
+
+	contract := new(HistoryContract)
+	result, err := contract.GetRecord(ctx, "REC001")
+
+	assert.NoError(t, err)
+	assert.Equal(t, &record, result)
+	t.Log("GetRecord returned the current state")
+}
+
+func TestGetRecordNotFound(t *testing.T) {
+	t.Log("Starting TestGetRecordNotFound: Verifying a missing key returns a clear error")
+	ctx, stub, _ := newMockContext("Org1MSP")
+	stub.On("GetState", "REC404").Return(nil, nil)
+
+	contract := new(HistoryContract)
+	result, err := contract.GetRecord(ctx, "REC404")
+
+	assert.Nil(t, result)
+	assert.EqualError(t, err, "record REC404 does not exist: record not found")
+}
+
+func TestGetLatestStatus(t *testing.T) {
+	t.Log("Starting TestGetLatestStatus: Verifying only the status is returned")
+	ctx, stub, _ := newMockContext("Org2MSP")
+	stub.On("GetState", "REC001").Return(mustMarshal(t, VerificationRecord{ID: "REC001", Description: "Manifest", Status: "PENDING"}), nil)
+
+	contract := new(HistoryContract)
+	status, err := contract.GetLatestStatus(ctx, "REC001")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "PENDING", status)
+}
+
+func TestGetLatestStatusNotFound(t *testing.T) {
+	ctx, stub, _ := newMockContext("Org2MSP")
+	stub.On("GetState", "REC404").Return(nil, nil)
+
+	contract := new(HistoryContract)
+	_, err := contract.GetLatestStatus(ctx, "REC404")
+
+	assert.EqualError(t, err, "record REC404 does not exist: record not found")
+}
+
+func TestGetRecordMalformedJSON(t *testing.T) {
+	t.Log("Starting TestGetRecordMalformedJSON: Verifying corrupt state is reported instead of returned")
+	ctx, stub, _ := newMockContext("Org1MSP")
+	stub.On("GetState", "REC001").Return([]byte(`{"id":"REC001",`), nil)
+
+	contract := new(HistoryContract)
+	result, err := contract.GetRecord(ctx, "REC001")
+
+	assert.Nil(t, result)
+	assert.ErrorContains(t, err, "failed to unmarshal record REC001")
+}
+
+func TestGetRecordMasksConfidentialDescription(t *testing.T) {
+	t.Log("Starting TestGetRecordMasksConfidentialDescription: Verifying non-owners see a redacted description")
+	ctx := new(MockTransactionContext)
+	stub := new(MockChaincodeStub)
+	clientIdentity := new(MockClientIdentity)
+
+	ctx.On("GetStub").Return(stub)
+	ctx.On("GetClientIdentity").Return(clientIdentity)
+
+	record := VerificationRecord{ID: "REC010", Description: "Supplier pricing", Party: "Org2MSP", Status: "CREATED", Confidential: true}
+	recordBytes, _ := json.Marshal(record)
+	stub.On("GetState", "REC010").Return(recordBytes, nil)
+	clientIdentity.On("GetMSPID").Return("Org3MSP", nil)
+
+	t.Log("Invoking GetRecord as a non-owning party...")
+	contract := new(HistoryContract)
+	result, err := contract.GetRecord(ctx, "REC010")
+
+	assert.NoError(t, err)
+	assert.Equal(t, redactedValue, result.Description)
+	assert.Equal(t, "Org2MSP", result.Party)
+	assert.Equal(t, "CREATED", result.Status)
+	t.Log("Confidential description was masked while the rest of the record was returned")
+}
+
+func TestGetRecordConfidentialVisibleToOwner(t *testing.T) {
+	t.Log("Starting TestGetRecordConfidentialVisibleToOwner: Verifying the owner sees the real description")
+	ctx := new(MockTransactionContext)
+	stub := new(MockChaincodeStub)
+	clientIdentity := new(MockClientIdentity)
+
+	ctx.On("GetStub").Return(stub)
+	ctx.On("GetClientIdentity").Return(clientIdentity)
+
+	record := VerificationRecord{ID: "REC010", Description: "Supplier pricing", Party: "Org2MSP", Status: "CREATED", Confidential: true}
+	recordBytes, _ := json.Marshal(record)
+	stub.On("GetState", "REC010").Return(recordBytes, nil)
+	clientIdentity.On("GetMSPID").Return("Org2MSP", nil)
+
+	contract := new(HistoryContract)
+	result, err := contract.GetRecord(ctx, "REC010")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "Supplier pricing", result.Description)
+}
+
+func TestCreateRecordRequiresCreatorAttribute(t *testing.T) {
+	t.Log("Starting TestCreateRecordRequiresCreatorAttribute: Verifying callers without the creator attribute are rejected")
+	contract := new(HistoryContract)
+
+	// A different role and a missing attribute must both be refused before anything is written
+	callers := map[string][]interface{}{
+		"wrong role":        {"operator", true, nil},
+		"missing attribute": {"", false, nil},
+	}
+	for name, attribute := range callers {
+		ctx, stub, clientIdentity := newMockContext("Org2MSP")
+		stub.On("GetState", compositeKey(configObjectType, configWorkflowGraph)).Return(nil, nil)
+		clientIdentity.On("GetAttributeValue", CreatorAttribute).Return(attribute...)
+
+		err := contract.CreateRecord(ctx, "REC001", "Initial Draft", "CREATED", "", 0, "")
+
+		assert.EqualError(t, err, "caller must hold attribute role=auditor to create records: unauthorized", name)
+		stub.AssertNotCalled(t, "PutState", mock.Anything, mock.Anything)
+	}
+	t.Log("Both unauthorized callers were rejected without touching the ledger")
+}
+
+func TestImportsRequireCreatorAttribute(t *testing.T) {
+	t.Log("Starting TestImportsRequireCreatorAttribute: Verifying imports and private creates check the creator attribute")
+	contract := new(HistoryContract)
+
+	creates := map[string]func(ctx *MockTransactionContext) error{
+		"BatchImport": func(ctx *MockTransactionContext) error {
+			_, err := contract.BatchImport(ctx, `[{"id":"IMP001","description":"Imported record","status":"CREATED"}]`, "", BatchModeAbort)
+			return err
+		},
+		"BatchImportCSV": func(ctx *MockTransactionContext) error {
+			_, err := contract.BatchImportCSV(ctx, "id,description,status\nIMP001,Imported record,CREATED\n")
+			return err
+		},
+		"CreatePrivateRecord": func(ctx *MockTransactionContext) error {
+			return contract.CreatePrivateRecord(ctx, "IMP001")
+		},
+	}
+
+	for name, create := range creates {
+		ctx, stub, clientIdentity := newMockContext("Org2MSP")
+		clientIdentity.On("GetAttributeValue", CreatorAttribute).Return("operator", true, nil)
+		stub.On("GetState", compositeKey(configObjectType, configWorkflowGraph)).Return(nil, nil)
+		stub.On("GetTransient").Return(map[string][]byte{"record": []byte(`{"id":"IMP001","description":"Private terms","status":"CREATED"}`)}, nil)
+
+		err := create(ctx)
+
+		assert.EqualError(t, err, "caller must hold attribute role=auditor to create records: unauthorized", name)
+		stub.AssertNotCalled(t, "PutState", mock.Anything, mock.Anything)
+		stub.AssertNotCalled(t, "PutPrivateData", mock.Anything, mock.Anything, mock.Anything)
+	}
+	t.Log("Every create path refused the caller without the attribute")
+}
+
+func TestCreateAndUpdateRecordMetadata(t *testing.T) {
+	t.Log("Starting TestCreateAndUpdateRecordMetadata: Verifying metadata is stored on create and replaced on update")
+	ctx, stub, clientIdentity := newMockContext("Org1MSP")
+	stub.On("GetState", compositeKey(configObjectType, configWorkflowGraph)).Return(nil, nil)
+	stub.On("GetState", compositeKey(configObjectType, configAuthorizedMSPs)).Return(nil, nil)
+	clientIdentity.On("GetAttributeValue", CreatorAttribute).Return(CreatorAttributeValue, true, nil)
+
+	stub.On("GetState", "REC001").Return(nil, nil).Twice()
+	stub.On("GetTxTimestamp").Return(timestamppb.Now(), nil)
+	var written VerificationRecord
+	stub.On("PutState", "REC001", mock.Anything).Run(func(args mock.Arguments) {
+		written = VerificationRecord{}
+		_ = json.Unmarshal(args.Get(1).([]byte), &written)
+	}).Return(nil)
+	stub.On("PutState", mock.Anything, indexEntryValue).Return(nil)
+	stub.On("DelState", mock.Anything).Return(nil)
+	stub.On("SetEvent", mock.Anything, mock.Anything).Return(nil)
+
+	contract := new(HistoryContract)
+	err := contract.CreateRecord(ctx, "REC001", "Initial Draft", "CREATED", `{"location":"Nairobi","batch":"B42"}`, 0, "")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"location": "Nairobi", "batch": "B42"}, written.Metadata)
+
+	stub.On("GetState", "REC001").Return(mustMarshal(t, written), nil)
+
+	// An empty argument keeps the metadata, a JSON object replaces it
+	err = contract.UpdateRecord(ctx, "REC001", "Submitted", "PENDING", "", 1)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"location": "Nairobi", "batch": "B42"}, written.Metadata)
+
+	err = contract.UpdateRecord(ctx, "REC001", "Submitted", "PENDING", `{"location":"Mombasa"}`, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"location": "Mombasa"}, written.Metadata)
+	t.Log("Metadata was set on create, kept by a plain update and replaced by an explicit one")
+}
+
+func TestCreateRecordRejectsInvalidMetadata(t *testing.T) {
+	ctx, stub, _ := newMockContext("Org1MSP")
+
+	contract := new(HistoryContract)
+	for _, metadataJSON := range []string{`["location"]`, `{"weight":12}`, `{"":"empty key"}`} {
+		err := contract.CreateRecord(ctx, "REC001", "Initial Draft", "CREATED", metadataJSON, 0, "")
+		assert.Error(t, err, metadataJSON)
+	}
+	stub.AssertNotCalled(t, "PutState", mock.Anything, mock.Anything)
+}
+
+func TestCreateOrGetRecordCreates(t *testing.T) {
+	t.Log("Starting TestCreateOrGetRecordCreates: Verifying an absent record is created")
+	ctx, stub, clientIdentity := newMockContext("Org1MSP")
+	stub.On("GetState", compositeKey(configObjectType, configWorkflowGraph)).Return(nil, nil)
+	stub.On("GetState", compositeKey(configObjectType, configAuthorizedMSPs)).Return(nil, nil)
+	clientIdentity.On("GetAttributeValue", CreatorAttribute).Return(CreatorAttributeValue, true, nil)
+
+	stub.On("GetState", "REC001").Return(nil, nil)
+	stub.On("GetTxTimestamp").Return(timestamppb.Now(), nil)
+	stub.On("PutState", "REC001", mock.Anything).Return(nil)
+	stub.On("PutState", mock.Anything, indexEntryValue).Return(nil)
+	stub.On("SetEvent", "RecordCreated", mock.Anything).Return(nil)
+
+	contract := new(HistoryContract)
+	record, err := contract.CreateOrGetRecord(ctx, "REC001", "Initial Draft", "CREATED")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "Initial Draft", record.Description)
+	assert.Equal(t, 1, record.Version)
+	stub.AssertCalled(t, "PutState", "REC001", mock.Anything)
+}
+
+func TestCreateOrGetRecordReturnsExisting(t *testing.T) {
+	t.Log("Starting TestCreateOrGetRecordReturnsExisting: Verifying a retry returns the stored record untouched")
+	ctx, stub, _ := newMockContext("Org1MSP")
+
+	existing := VerificationRecord{ID: "REC001", Description: "Initial Draft", Party: "Org1MSP", Status: "PENDING", Version: 2}
+	stub.On("GetState", "REC001").Return(mustMarshal(t, existing), nil)
+
+	contract := new(HistoryContract)
+	record, err := contract.CreateOrGetRecord(ctx, "REC001", "Retried Draft", "CREATED")
+
+	assert.NoError(t, err)
+	assert.Equal(t, &existing, record)
+	stub.AssertNotCalled(t, "PutState", mock.Anything, mock.Anything)
+	t.Log("The existing PENDING record was returned without a write")
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// currentSchemaVersion is the VerificationRecord layout written by this chaincode version
+const currentSchemaVersion = 2
+
+// schemaMigrations fill in the defaults a record needs to reach each schema version, keyed by that version.
+// Every step must leave values the record already has untouched.
+var schemaMigrations = map[int]func(record *VerificationRecord){
+	// Version 2 introduced CreatedAt; older records only know when they were last written
+	2: func(record *VerificationRecord) {
+		if record.CreatedAt == "" {
+			record.CreatedAt = record.Timestamp
+		}
+	},
+}
+
+// schemaVersion returns the schema a record was written in, treating an unset version as 1
+func schemaVersion(record *VerificationRecord) int {
+	if record.SchemaVersion == 0 {
+		return 1
+	}
+	return record.SchemaVersion
+}
+
+// migrateRecordSchema applies the missing migration steps to a record in order and reports whether
+// anything had to be done. Records already at currentSchemaVersion are left as they are.
+func migrateRecordSchema(record *VerificationRecord) bool {
+	version := schemaVersion(record)
+	if version >= currentSchemaVersion {
+		return false
+	}
+	for version < currentSchemaVersion {
+		version++
+		schemaMigrations[version](record)
+	}
+	record.SchemaVersion = currentSchemaVersion
+	return true
+}
+
+// MigrateRecord rewrites a record in the current schema, filling in defaults for fields added since it was
+// last written (admin only). Migrating a record that is already current is a no-op, so it is safe to re-run.
+// The owning party is kept, CreatedAt is filled from the old Timestamp before it is refreshed like on any
+// other write, and sealed and locked records are refused.
+func (s *HistoryContract) MigrateRecord(ctx contractapi.TransactionContextInterface, id string) error {
+	if err := requireAdmin(ctx); err != nil {
+		return err
+	}
+
+	record, err := s.readRecord(ctx, id)
+	if err != nil {
+		return err
+	}
+	if schemaVersion(record) >= currentSchemaVersion {
+		return nil
+	}
+	if err := ensureMutable(record); err != nil {
+		return err
+	}
+
+	timestamp, err := txTimestampString(ctx)
+	if err != nil {
+		return err
+	}
+
+	migrateRecordSchema(record)
+	record.Timestamp = timestamp
+	return s.putRecord(ctx, record)
+}
+
+// MigrateAll runs MigrateRecord over every record in world state and returns how many were rewritten
+// (admin only). Records already in the current schema are not written again, and sealed or locked records
+// are left behind until they can change. Re-running it after a complete migration reports zero.
+func (s *HistoryContract) MigrateAll(ctx contractapi.TransactionContextInterface) (int, error) {
+	if err := requireAdmin(ctx); err != nil {
+		return 0, err
+	}
+
+	records, err := s.getAllRecords(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	timestamp, err := txTimestampString(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	migrated := 0
+	for _, record := range records {
+		if schemaVersion(record) >= currentSchemaVersion || ensureMutable(record) != nil {
+			continue
+		}
+
+		migrateRecordSchema(record)
+		record.Timestamp = timestamp
+		if err := s.putRecord(ctx, record); err != nil {
+			return migrated, fmt.Errorf("failed to migrate record %s: %v", record.ID, err)
+		}
+		migrated++
+	}
+	return migrated, nil
+}
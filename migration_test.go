@@ -0,0 +1,102 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-protos-go/ledger/queryresult"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestMigrateRecordIsIdempotent(t *testing.T) {
+	t.Log("Starting TestMigrateRecordIsIdempotent: Verifying a v1 record is migrated to v2 once")
+	ctx, stub, _ := newMockContext(AdminMSPID)
+
+	legacy := VerificationRecord{ID: "REC001", Description: "Manifest", Party: "Org2MSP", Status: "PENDING", Timestamp: "2023-06-01T08:00:00Z", Version: 3}
+	stub.On("GetState", "REC001").Return(mustMarshal(t, legacy), nil).Twice()
+	stub.On("GetTxTimestamp").Return(timestamppb.New(time.Date(2024, 3, 1, 8, 0, 0, 0, time.UTC)), nil)
+	var written VerificationRecord
+	stub.On("PutState", "REC001", mock.Anything).Run(func(args mock.Arguments) {
+		_ = json.Unmarshal(args.Get(1).([]byte), &written)
+	}).Return(nil).Once()
+
+	contract := new(HistoryContract)
+	err := contract.MigrateRecord(ctx, "REC001")
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, written.SchemaVersion)
+	assert.Equal(t, "2023-06-01T08:00:00Z", written.CreatedAt)
+	assert.Equal(t, "2024-03-01T08:00:00Z", written.Timestamp)
+	assert.Equal(t, "Org2MSP", written.Party)
+	assert.Equal(t, 4, written.Version)
+
+	t.Log("Re-running the migration on the migrated record...")
+	stub.On("GetState", "REC001").Return(mustMarshal(t, written), nil)
+	err = contract.MigrateRecord(ctx, "REC001")
+
+	assert.NoError(t, err)
+	stub.AssertNumberOfCalls(t, "PutState", 1)
+	t.Log("The second run found nothing to do and wrote nothing")
+}
+
+func TestMigrateAllCountsMigratedRecords(t *testing.T) {
+	t.Log("Starting TestMigrateAllCountsMigratedRecords: Verifying only outdated, writable records are migrated")
+	ctx, stub, _ := newMockContext(AdminMSPID)
+
+	legacy := VerificationRecord{ID: "REC001", Status: "CREATED", Timestamp: "2023-06-01T08:00:00Z"}
+	current := VerificationRecord{ID: "REC002", Status: "CREATED", CreatedAt: "2024-01-01T08:00:00Z", SchemaVersion: currentSchemaVersion}
+	sealed := VerificationRecord{ID: "REC003", Status: "VERIFIED", Sealed: true}
+	stub.On("GetStateByRange", "", "").Return(newKVIterator(
+		&queryresult.KV{Key: "REC001", Value: mustMarshal(t, legacy)},
+		&queryresult.KV{Key: "REC002", Value: mustMarshal(t, current)},
+		&queryresult.KV{Key: "REC003", Value: mustMarshal(t, sealed)},
+	), nil)
+	stub.On("GetState", "REC001").Return(mustMarshal(t, legacy), nil)
+	stub.On("GetTxTimestamp").Return(timestamppb.New(time.Date(2024, 3, 1, 8, 0, 0, 0, time.UTC)), nil)
+	stub.On("PutState", "REC001", mock.Anything).Return(nil)
+
+	contract := new(HistoryContract)
+	migrated, err := contract.MigrateAll(ctx)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, migrated)
+	stub.AssertNotCalled(t, "PutState", "REC002", mock.Anything)
+	stub.AssertNotCalled(t, "PutState", "REC003", mock.Anything)
+	t.Log("Only the legacy record was rewritten")
+}
+
+func TestMigrateRecordRequiresAdmin(t *testing.T) {
+	ctx, stub, _ := newMockContext("Org2MSP")
+
+	contract := new(HistoryContract)
+	err := contract.MigrateRecord(ctx, "REC001")
+
+	assert.ErrorIs(t, err, ErrUnauthorized)
+	stub.AssertNotCalled(t, "GetState", "REC001")
+}
+
+func TestSchemaMigrationsCoverEveryVersion(t *testing.T) {
+	for version := 2; version <= currentSchemaVersion; version++ {
+		assert.Contains(t, schemaMigrations, version)
+	}
+}
+
+func TestNewRecordsUseCurrentSchema(t *testing.T) {
+	ctx, stub, _ := newMockContext("Org1MSP")
+	stub.On("GetState", "IMP001").Return(nil, nil)
+	var written VerificationRecord
+	stub.On("PutState", "IMP001", mock.Anything).Run(func(args mock.Arguments) {
+		_ = json.Unmarshal(args.Get(1).([]byte), &written)
+	}).Return(nil)
+	stub.On("PutState", mock.Anything, indexEntryValue).Return(nil)
+
+	contract := new(HistoryContract)
+	err := contract.putRecord(ctx, &VerificationRecord{ID: "IMP001", Status: "CREATED", Timestamp: "2024-03-10T09:00:00Z"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, currentSchemaVersion, written.SchemaVersion)
+	assert.Equal(t, "2024-03-10T09:00:00Z", written.CreatedAt)
+}
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// claimTokenObjectType prefixes the side keys holding claim token hashes
+const claimTokenObjectType = "claimToken"
+
+// OwnershipTransferEvent is the payload of the RecordTransferred chaincode event
+type OwnershipTransferEvent struct {
+	ID        string `json:"id"`
+	FromParty string `json:"fromParty"`
+	ToParty   string `json:"toParty"`
+	Timestamp string `json:"timestamp"`
+}
+
+// OwnershipEntry is one custodian in a record's chain of custody and the time it took ownership
+type OwnershipEntry struct {
+	Party string    `json:"party"`
+	Since time.Time `json:"since"`
+}
+
+// SetClaimToken arms an unowned record (empty Party, e.g. an anonymous import) for a later ClaimRecord (admin only).
+// Only the SHA-256 hex digest of the token is submitted and stored, so the token itself never appears on the
+// ledger before it is used.
+func (s *HistoryContract) SetClaimToken(ctx contractapi.TransactionContextInterface, id string, tokenHash string) error {
+	if err := requireAdmin(ctx); err != nil {
+		return err
+	}
+	if !isSHA256Hex(tokenHash) {
+		return fmt.Errorf("token hash must be a 64 character hex SHA-256 digest")
+	}
+
+	record, err := s.readRecord(ctx, id)
+	if err != nil {
+		return err
+	}
+	if record.Party != "" {
+		return fmt.Errorf("record %s is already owned by %s", id, record.Party)
+	}
+
+	tokenKey, err := ctx.GetStub().CreateCompositeKey(claimTokenObjectType, []string{id})
+	if err != nil {
+		return fmt.Errorf("failed to create claim token key for %s: %v", id, err)
+	}
+	return ctx.GetStub().PutState(tokenKey, []byte(strings.ToLower(tokenHash)))
+}
+
+// ClaimRecord lets the caller take ownership of an unowned record by presenting the token armed with
+// SetClaimToken. The token is single use and cleared once the claim succeeds.
+func (s *HistoryContract) ClaimRecord(ctx contractapi.TransactionContextInterface, id string, challengeToken string) error {
+	record, err := s.readRecord(ctx, id)
+	if err != nil {
+		return err
+	}
+	if record.Party != "" {
+		return fmt.Errorf("record %s is already owned by %s", id, record.Party)
+	}
+
+	tokenKey, err := ctx.GetStub().CreateCompositeKey(claimTokenObjectType, []string{id})
+	if err != nil {
+		return fmt.Errorf("failed to create claim token key for %s: %v", id, err)
+	}
+	storedHash, err := ctx.GetStub().GetState(tokenKey)
+	if err != nil {
+		return fmt.Errorf("failed to read claim token for %s: %v", id, err)
+	}
+	if storedHash == nil {
+		return fmt.Errorf("record %s has no claim token set", id)
+	}
+
+	presented := sha256.Sum256([]byte(challengeToken))
+	if subtle.ConstantTimeCompare([]byte(hex.EncodeToString(presented[:])), storedHash) != 1 {
+		return fmt.Errorf("invalid claim token for record %s", id)
+	}
+
+	clientIdentity, err := ctx.GetClientIdentity().GetMSPID()
+	if err != nil {
+		return fmt.Errorf("failed to get client identity: %v", err)
+	}
+	timestamp, err := txTimestampString(ctx)
+	if err != nil {
+		return err
+	}
+
+	record.Party = clientIdentity
+	record.Timestamp = timestamp
+	if err := s.putRecord(ctx, record); err != nil {
+		return err
+	}
+
+	return ctx.GetStub().DelState(tokenKey)
+}
+
+// TransferOwnership hands custody of a record to newParty and emits a RecordTransferred event.
+// Only the current owner may transfer; unlike regular updates there is no admin override, so custody
+// can never move without the holder's consent.
+func (s *HistoryContract) TransferOwnership(ctx contractapi.TransactionContextInterface, id string, newParty string) error {
+	if newParty == "" {
+		return fmt.Errorf("new party must not be empty")
+	}
+
+	record, err := s.readRecord(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	clientIdentity, err := ctx.GetClientIdentity().GetMSPID()
+	if err != nil {
+		return fmt.Errorf("failed to get client identity: %v", err)
+	}
+	if clientIdentity != record.Party {
+		return fmt.Errorf("caller %s is not authorized to transfer record owned by %s: %w", clientIdentity, record.Party, ErrUnauthorized)
+	}
+
+	timestamp, err := txTimestampString(ctx)
+	if err != nil {
+		return err
+	}
+
+	record.Party = newParty
+	record.Timestamp = timestamp
+	if err := s.putRecord(ctx, record); err != nil {
+		return err
+	}
+
+	eventJSON, err := json.Marshal(OwnershipTransferEvent{ID: id, FromParty: clientIdentity, ToParty: newParty, Timestamp: timestamp})
+	if err != nil {
+		return err
+	}
+	if err := ctx.GetStub().SetEvent("RecordTransferred", eventJSON); err != nil {
+		return fmt.Errorf("failed to emit RecordTransferred event for %s: %v", id, err)
+	}
+	return nil
+}
+
+// GetRecordOwnershipHistory returns the sequence of parties that held a record, oldest first, each with the
+// ledger time it took ownership. Consecutive versions by the same party collapse into one entry. As in
+// forEachHandoff, a delete ends the chain, so the owner of a re-created record starts a new entry.
+func (s *HistoryContract) GetRecordOwnershipHistory(ctx contractapi.TransactionContextInterface, id string) ([]OwnershipEntry, error) {
+	history, err := s.getChronologicalHistory(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	owners := []OwnershipEntry{}
+	previousParty := ""
+	for _, entry := range history {
+		if entry.IsDelete || entry.Record == nil {
+			previousParty = ""
+			continue
+		}
+
+		party := entry.Record.Party
+		if party != "" && party != previousParty {
+			owners = append(owners, OwnershipEntry{Party: party, Since: entry.Timestamp})
+		}
+		previousParty = party
+	}
+	return owners, nil
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestClaimRecordWithChallengeToken(t *testing.T) {
+	t.Log("Starting TestClaimRecordWithChallengeToken: Verifying a correct token claims and a wrong one is rejected")
+	unowned := VerificationRecord{ID: "IMP001", Status: "CREATED"}
+	tokenKey := compositeKey(claimTokenObjectType, "IMP001")
+	sum := sha256.Sum256([]byte("open-sesame"))
+	tokenHash := hex.EncodeToString(sum[:])
+	contract := new(HistoryContract)
+
+	t.Log("Arming the record with a token hash as admin...")
+	adminCtx, adminStub, _ := newMockContext(AdminMSPID)
+	adminStub.On("GetState", "IMP001").Return(mustMarshal(t, unowned), nil)
+	adminStub.On("PutState", tokenKey, []byte(tokenHash)).Return(nil)
+	assert.NoError(t, contract.SetClaimToken(adminCtx, "IMP001", tokenHash))
+	adminStub.AssertExpectations(t)
+
+	t.Log("Claiming with the wrong token...")
+	ctx, stub, _ := newMockContext("Org2MSP")
+	stub.On("GetState", "IMP001").Return(mustMarshal(t, unowned), nil)
+	stub.On("GetState", tokenKey).Return([]byte(tokenHash), nil)
+
+	err := contract.ClaimRecord(ctx, "IMP001", "guess")
+	assert.EqualError(t, err, "invalid claim token for record IMP001")
+	stub.AssertNotCalled(t, "PutState", mock.Anything, mock.Anything)
+
+	t.Log("Claiming with the correct token...")
+	var claimed VerificationRecord
+	stub.On("GetTxTimestamp").Return(timestamppb.New(time.Date(2024, 3, 1, 8, 0, 0, 0, time.UTC)), nil)
+	stub.On("PutState", "IMP001", mock.Anything).Run(func(args mock.Arguments) {
+		_ = json.Unmarshal(args.Get(1).([]byte), &claimed)
+	}).Return(nil)
+	stub.On("PutState", compositeKey(partyIndex, "Org2MSP", "IMP001"), indexEntryValue).Return(nil)
+	stub.On("DelState", tokenKey).Return(nil)
+
+	err = contract.ClaimRecord(ctx, "IMP001", "open-sesame")
+	assert.NoError(t, err)
+	assert.Equal(t, "Org2MSP", claimed.Party)
+	stub.AssertCalled(t, "DelState", tokenKey)
+	t.Log("The record is now owned by Org2MSP and the token was cleared")
+}
+
+func TestClaimRecordAlreadyOwned(t *testing.T) {
+	ctx, stub, _ := newMockContext("Org2MSP")
+	stub.On("GetState", "REC001").Return(mustMarshal(t, VerificationRecord{ID: "REC001", Party: "Org1MSP"}), nil)
+
+	contract := new(HistoryContract)
+	err := contract.ClaimRecord(ctx, "REC001", "anything")
+
+	assert.EqualError(t, err, "record REC001 is already owned by Org1MSP")
+}
+
+func TestTransferOwnership(t *testing.T) {
+	t.Log("Starting TestTransferOwnership: Verifying the owner can hand custody to another party")
+	ctx, stub, _ := newMockContext("Org2MSP")
+
+	stub.On("GetState", "REC001").Return(mustMarshal(t, VerificationRecord{ID: "REC001", Party: "Org2MSP", Status: "PENDING"}), nil)
+	stub.On("GetTxTimestamp").Return(timestamppb.New(time.Date(2024, 3, 1, 8, 0, 0, 0, time.UTC)), nil)
+	var transferred VerificationRecord
+	stub.On("PutState", "REC001", mock.Anything).Run(func(args mock.Arguments) {
+		_ = json.Unmarshal(args.Get(1).([]byte), &transferred)
+	}).Return(nil)
+	stub.On("DelState", compositeKey(partyIndex, "Org2MSP", "REC001")).Return(nil)
+	stub.On("PutState", compositeKey(partyIndex, "Org3MSP", "REC001"), indexEntryValue).Return(nil)
+	var eventPayload []byte
+	stub.On("SetEvent", "RecordTransferred", mock.Anything).Run(func(args mock.Arguments) {
+		eventPayload = args.Get(1).([]byte)
+	}).Return(nil)
+
+	contract := new(HistoryContract)
+	err := contract.TransferOwnership(ctx, "REC001", "Org3MSP")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "Org3MSP", transferred.Party)
+	var event OwnershipTransferEvent
+	assert.NoError(t, json.Unmarshal(eventPayload, &event))
+	assert.Equal(t, OwnershipTransferEvent{ID: "REC001", FromParty: "Org2MSP", ToParty: "Org3MSP", Timestamp: "2024-03-01T08:00:00Z"}, event)
+	stub.AssertExpectations(t)
+	t.Log("Custody moved to Org3MSP and the transfer was announced")
+}
+
+func TestTransferOwnershipByNonOwner(t *testing.T) {
+	ctx, stub, _ := newMockContext("Org3MSP")
+	stub.On("GetState", "REC001").Return(mustMarshal(t, VerificationRecord{ID: "REC001", Party: "Org2MSP"}), nil)
+
+	contract := new(HistoryContract)
+	err := contract.TransferOwnership(ctx, "REC001", "Org3MSP")
+
+	assert.EqualError(t, err, "caller Org3MSP is not authorized to transfer record owned by Org2MSP: unauthorized")
+	stub.AssertNotCalled(t, "PutState", mock.Anything, mock.Anything)
+}
+
+func TestGetRecordOwnershipHistory(t *testing.T) {
+	t.Log("Starting TestGetRecordOwnershipHistory: Verifying repeated owners collapse into one custody entry")
+	ctx, stub, _ := newMockContext("Org1MSP")
+
+	base := time.Date(2024, 3, 1, 8, 0, 0, 0, time.UTC)
+	stub.On("GetHistoryForKey", "REC001").Return(newHistoryIterator(
+		historyEntry(t, "tx4", base.Add(3*time.Hour), &VerificationRecord{ID: "REC001", Party: "Org3MSP", Status: "VERIFIED"}),
+		historyEntry(t, "tx3", base.Add(2*time.Hour), &VerificationRecord{ID: "REC001", Party: "Org2MSP", Status: "PENDING"}),
+		historyEntry(t, "tx2", base.Add(time.Hour), &VerificationRecord{ID: "REC001", Party: "Org2MSP", Status: "CREATED"}),
+		historyEntry(t, "tx1", base, &VerificationRecord{ID: "REC001", Party: "Org1MSP", Status: "CREATED"}),
+	), nil)
+
+	contract := new(HistoryContract)
+	owners, err := contract.GetRecordOwnershipHistory(ctx, "REC001")
+
+	assert.NoError(t, err)
+	assert.Equal(t, []OwnershipEntry{
+		{Party: "Org1MSP", Since: base},
+		{Party: "Org2MSP", Since: base.Add(time.Hour)},
+		{Party: "Org3MSP", Since: base.Add(3 * time.Hour)},
+	}, owners)
+	t.Log("Org1 -> Org2 -> Org2 -> Org3 collapsed to three custodians")
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// RegisterPartyName maps a human friendly display name (e.g. "Thika Growers") to an MSP ID (admin only)
+func (s *HistoryContract) RegisterPartyName(ctx contractapi.TransactionContextInterface, displayName string, mspID string) error {
+	if err := requireAdmin(ctx); err != nil {
+		return err
+	}
+	if displayName == "" {
+		return fmt.Errorf("display name must not be empty")
+	}
+	if mspID == "" {
+		return fmt.Errorf("MSP ID must not be empty")
+	}
+
+	return putConfigValue(ctx, configPartyName, mspID, displayName)
+}
+
+// QueryRecordsByPartyName resolves a registered display name to its MSP ID and runs QueryRecordsByParty.
+// This uses a rich query and therefore requires the CouchDB state database.
+func (s *HistoryContract) QueryRecordsByPartyName(ctx contractapi.TransactionContextInterface, displayName string) ([]*VerificationRecord, error) {
+	mspID, found, err := getConfigValue(ctx, configPartyName, displayName)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, fmt.Errorf("party display name %q is not registered", displayName)
+	}
+
+	return s.QueryRecordsByParty(ctx, mspID)
+}
+
+// GetAllParties returns the sorted, distinct set of parties that own at least one record
+func (s *HistoryContract) GetAllParties(ctx contractapi.TransactionContextInterface) ([]string, error) {
+	records, err := s.getAllRecords(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	parties := []string{}
+	for _, record := range records {
+		if record.Party == "" || seen[record.Party] {
+			continue
+		}
+		seen[record.Party] = true
+		parties = append(parties, record.Party)
+	}
+
+	sort.Strings(parties)
+	return parties, nil
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestQueryRecordsByPartyName(t *testing.T) {
+	t.Log("Starting TestQueryRecordsByPartyName: Verifying a display name resolves to its party's records")
+	ctx, stub, _ := newMockContext(AdminMSPID)
+
+	nameKey := compositeKey(configObjectType, configPartyName, "Thika Growers")
+	stub.On("PutState", nameKey, []byte("Org2MSP")).Return(nil)
+
+	t.Log("Registering the display name as admin...")
+	contract := new(HistoryContract)
+	assert.NoError(t, contract.RegisterPartyName(ctx, "Thika Growers", "Org2MSP"))
+
+	stub.On("GetState", nameKey).Return([]byte("Org2MSP"), nil)
+	stub.On("GetQueryResult", `{"selector":{"party":"Org2MSP"}}`).Return(newStateIterator(t,
+		VerificationRecord{ID: "REC002", Party: "Org2MSP", Status: "PENDING"},
+	), nil)
+
+	t.Log("Querying by display name...")
+	records, err := contract.QueryRecordsByPartyName(ctx, "Thika Growers")
+
+	assert.NoError(t, err)
+	assert.Len(t, records, 1)
+	assert.Equal(t, "REC002", records[0].ID)
+	t.Log("The display name resolved to Org2MSP and its record was returned")
+}
+
+func TestQueryRecordsByPartyNameUnregistered(t *testing.T) {
+	ctx, stub, _ := newMockContext("Org2MSP")
+	stub.On("GetState", compositeKey(configObjectType, configPartyName, "Nobody")).Return(nil, nil)
+
+	contract := new(HistoryContract)
+	_, err := contract.QueryRecordsByPartyName(ctx, "Nobody")
+
+	assert.EqualError(t, err, `party display name "Nobody" is not registered`)
+	stub.AssertNotCalled(t, "GetQueryResult", mock.Anything)
+}
+
+func TestGetAllParties(t *testing.T) {
+	t.Log("Starting TestGetAllParties: Verifying distinct owning parties are listed in order")
+	ctx, stub, _ := newMockContext("Org1MSP")
+
+	records := []VerificationRecord{
+		{ID: "REC001", Party: "Org2MSP"},
+		{ID: "REC002", Party: "Org1MSP"},
+		{ID: "REC003", Party: "Org2MSP"},
+		{ID: "REC004", Party: ""},
+	}
+	stub.On("GetStateByRange", "", "").Return(newStateIterator(t, records...), nil)
+
+	contract := new(HistoryContract)
+	parties, err := contract.GetAllParties(ctx)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Org1MSP", "Org2MSP"}, parties)
+	t.Log("Two distinct parties returned, sorted and without the empty owner")
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// RecordPatch lists the fields PatchRecord may change. A nil field is left untouched.
+type RecordPatch struct {
+	Description  *string            `json:"description"`
+	Status       *string            `json:"status"`
+	Confidential *bool              `json:"confidential"`
+	Metadata     *map[string]string `json:"metadata"`
+	Quantity     *int64             `json:"quantity"`
+}
+
+// PatchRecord applies a partial JSON update such as {"status":"VERIFIED"}, overwriting only the fields
+// present in the patch. Unknown or read-only fields are rejected. As with UpdateRecord, only the owner or
+// the admin MSP may patch, status changes must follow the workflow, the patched record must pass the same
+// validation, and the Timestamp is refreshed from the transaction while the record keeps its owner.
+func (s *HistoryContract) PatchRecord(ctx contractapi.TransactionContextInterface, id string, patch string) error {
+	decoder := json.NewDecoder(bytes.NewReader([]byte(patch)))
+	decoder.DisallowUnknownFields()
+
+	var changes RecordPatch
+	if err := decoder.Decode(&changes); err != nil {
+		return fmt.Errorf("failed to unmarshal patch: %v", err)
+	}
+
+	record, err := s.readRecord(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := ensureMutable(record); err != nil {
+		return err
+	}
+
+	if _, err := authorizeOwner(ctx, record, "patch"); err != nil {
+		return err
+	}
+
+	oldStatus := record.Status
+	if changes.Status != nil {
+		if err := requireKnownStatus(ctx, *changes.Status); err != nil {
+			return err
+		}
+		allowed, err := isValidTransition(ctx, record.Status, *changes.Status)
+		if err != nil {
+			return err
+		}
+		if !allowed {
+			return fmt.Errorf("invalid status transition from %s to %s: %w", record.Status, *changes.Status, ErrInvalidStatus)
+		}
+		record.Status = *changes.Status
+	}
+	if changes.Description != nil {
+		record.Description = *changes.Description
+	}
+	if changes.Confidential != nil {
+		record.Confidential = *changes.Confidential
+	}
+	if changes.Metadata != nil {
+		if err := validateMetadata(*changes.Metadata); err != nil {
+			return err
+		}
+		record.Metadata = *changes.Metadata
+	}
+	if changes.Quantity != nil {
+		record.Quantity = *changes.Quantity
+	}
+
+	graph, err := getWorkflowGraph(ctx)
+	if err != nil {
+		return err
+	}
+	if err := validateRecord(record, graph); err != nil {
+		return err
+	}
+
+	timestamp, err := txTimestampString(ctx)
+	if err != nil {
+		return err
+	}
+	record.Timestamp = timestamp
+
+	if err := s.putRecord(ctx, record); err != nil {
+		return err
+	}
+	return emitStatusChange(ctx, oldStatus, record)
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestPatchRecordStatusOnly(t *testing.T) {
+	t.Log("Starting TestPatchRecordStatusOnly: Verifying a status-only patch preserves the description")
+	ctx, stub, _ := newMockContext("Org2MSP")
+
+	existing := VerificationRecord{ID: "REC001", Description: "Shipping Manifest", Party: "Org2MSP", Status: "PENDING",
+		Metadata: map[string]string{"location": "Thika"}}
+	stub.On("GetState", "REC001").Return(mustMarshal(t, existing), nil)
+	stub.On("GetState", compositeKey(configObjectType, configWorkflowGraph)).Return(nil, nil)
+	stub.On("GetTxTimestamp").Return(timestamppb.Now(), nil)
+
+	var written VerificationRecord
+	stub.On("PutState", "REC001", mock.Anything).Run(func(args mock.Arguments) {
+		_ = json.Unmarshal(args.Get(1).([]byte), &written)
+	}).Return(nil)
+	stub.On("PutState", mock.Anything, indexEntryValue).Return(nil)
+	stub.On("DelState", mock.Anything).Return(nil)
+	stub.On("SetEvent", "RecordUpdated", mock.Anything).Return(nil)
+
+	contract := new(HistoryContract)
+	err := contract.PatchRecord(ctx, "REC001", `{"status":"VERIFIED"}`)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "VERIFIED", written.Status)
+	assert.Equal(t, "Shipping Manifest", written.Description)
+	assert.Equal(t, map[string]string{"location": "Thika"}, written.Metadata)
+	assert.Equal(t, "Org2MSP", written.Party)
+	assert.NotEmpty(t, written.Timestamp)
+	t.Log("Only the status changed; description and metadata were preserved")
+}
+
+func TestPatchRecordRejectsUnknownFields(t *testing.T) {
+	t.Log("Starting TestPatchRecordRejectsUnknownFields: Verifying read-only or unknown fields are refused")
+	ctx, stub, _ := newMockContext("Org2MSP")
+
+	contract := new(HistoryContract)
+	err := contract.PatchRecord(ctx, "REC001", `{"party":"Org3MSP"}`)
+
+	assert.EqualError(t, err, `failed to unmarshal patch: json: unknown field "party"`)
+	stub.AssertNotCalled(t, "PutState", mock.Anything, mock.Anything)
+}
+
+func TestPatchRecordValidatesMergedRecord(t *testing.T) {
+	t.Log("Starting TestPatchRecordValidatesMergedRecord: Verifying patches get the same checks as UpdateRecord")
+	contract := new(HistoryContract)
+
+	patches := map[string]string{
+		`{"description":""}`:        "description of record REC001 must not be empty",
+		`{"metadata":{"":"Thika"}}`: "metadata keys must not be empty",
+		`{"quantity":-1}`:           "quantity of record REC001 must not be negative, got -1",
+		`{"description":"   "}`:     "description of record REC001 must not be empty",
+	}
+	for patch, expected := range patches {
+		ctx, stub, _ := newMockContext(AdminMSPID)
+		stub.On("GetState", "REC001").Return(mustMarshal(t, VerificationRecord{ID: "REC001", Description: "Shipping Manifest", Party: "Org2MSP", Status: "PENDING"}), nil)
+		stub.On("GetState", compositeKey(configObjectType, configWorkflowGraph)).Return(nil, nil)
+
+		err := contract.PatchRecord(ctx, "REC001", patch)
+
+		assert.EqualError(t, err, expected, patch)
+		stub.AssertNotCalled(t, "PutState", mock.Anything, mock.Anything)
+	}
+	t.Log("Every invalid patch was refused before anything was written")
+}
+
+func TestPatchRecordByAdminKeepsOwner(t *testing.T) {
+	ctx, stub, _ := newMockContext(AdminMSPID)
+	stub.On("GetState", "REC001").Return(mustMarshal(t, VerificationRecord{ID: "REC001", Description: "Shipping Manifest", Party: "Org2MSP", Status: "PENDING"}), nil)
+	stub.On("GetState", compositeKey(configObjectType, configWorkflowGraph)).Return(nil, nil)
+	stub.On("GetTxTimestamp").Return(timestamppb.Now(), nil)
+	var written VerificationRecord
+	stub.On("PutState", "REC001", mock.Anything).Run(func(args mock.Arguments) {
+		_ = json.Unmarshal(args.Get(1).([]byte), &written)
+	}).Return(nil)
+	stub.On("SetEvent", "RecordUpdated", mock.Anything).Return(nil)
+
+	contract := new(HistoryContract)
+	err := contract.PatchRecord(ctx, "REC001", `{"description":"Corrected manifest"}`)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "Corrected manifest", written.Description)
+	assert.Equal(t, "Org2MSP", written.Party)
+}
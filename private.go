@@ -0,0 +1,107 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// sensitiveCollection is the private data collection holding full record details.
+// The chaincode must be approved and committed with a collection definition for it, e.g.
+// `peer lifecycle chaincode approveformyorg ... --collections-config collections_config.json`;
+// without it every private read and write fails.
+const sensitiveCollection = "collectionSensitive"
+
+// transientRecordKey is the transient map entry carrying the private record payload
+const transientRecordKey = "record"
+
+// CreatePrivateRecord stores a record's full details in the sensitive private data collection.
+// The payload is read from the transient map entry "record" so it never lands in the transaction itself.
+// The payload goes through the same checks as CreateRecord. A public stub holding only the ID, owning party,
+// status and timestamps is written to world state so the record can still be discovered and managed by its owner.
+func (s *HistoryContract) CreatePrivateRecord(ctx contractapi.TransactionContextInterface, id string) error {
+	if err := validateRecordID(id); err != nil {
+		return err
+	}
+
+	transient, err := ctx.GetStub().GetTransient()
+	if err != nil {
+		return fmt.Errorf("failed to read transient data: %v", err)
+	}
+	payload, ok := transient[transientRecordKey]
+	if !ok || len(payload) == 0 {
+		return fmt.Errorf("transient map must contain the %q entry", transientRecordKey)
+	}
+
+	var record VerificationRecord
+	if err := json.Unmarshal(payload, &record); err != nil {
+		return fmt.Errorf("failed to unmarshal private record: %v", err)
+	}
+	if record.ID != id {
+		return fmt.Errorf("private record ID %s does not match %s", record.ID, id)
+	}
+
+	graph, err := getWorkflowGraph(ctx)
+	if err != nil {
+		return err
+	}
+	if err := validateRecord(&record, graph); err != nil {
+		return err
+	}
+
+	if err := requireCreatorAttribute(ctx); err != nil {
+		return err
+	}
+
+	exists, err := s.RecordExists(ctx, id)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return fmt.Errorf("the record %s already exists: %w", id, ErrRecordExists)
+	}
+
+	clientIdentity, err := ctx.GetClientIdentity().GetMSPID()
+	if err != nil {
+		return fmt.Errorf("failed to get client identity: %v", err)
+	}
+	if err := s.requireAuthorizedCreator(ctx, clientIdentity); err != nil {
+		return err
+	}
+	timestamp, err := txTimestampString(ctx)
+	if err != nil {
+		return err
+	}
+	record.Party = clientIdentity
+	record.Timestamp = timestamp
+	record.CreatedAt = timestamp
+
+	recordJSON, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	if err := ctx.GetStub().PutPrivateData(sensitiveCollection, id, recordJSON); err != nil {
+		return fmt.Errorf("failed to put private data for ID %s: %v", id, err)
+	}
+
+	return s.putRecord(ctx, &VerificationRecord{ID: id, Party: clientIdentity, Status: record.Status, Timestamp: timestamp, CreatedAt: timestamp})
+}
+
+// GetPrivateRecord returns the full record from the sensitive private data collection.
+// Only peers of organizations that are members of the collection hold the data.
+func (s *HistoryContract) GetPrivateRecord(ctx contractapi.TransactionContextInterface, id string) (*VerificationRecord, error) {
+	recordJSON, err := ctx.GetStub().GetPrivateData(sensitiveCollection, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read private data: %v", err)
+	}
+	if recordJSON == nil {
+		return nil, fmt.Errorf("private record %s does not exist: %w", id, ErrRecordNotFound)
+	}
+
+	var record VerificationRecord
+	if err := json.Unmarshal(recordJSON, &record); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal private record %s: %v", id, err)
+	}
+	return &record, nil
+}
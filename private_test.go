@@ -0,0 +1,88 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestCreatePrivateRecord(t *testing.T) {
+	t.Log("Starting TestCreatePrivateRecord: Verifying details go to the collection and only a stub is public")
+	ctx, stub, clientIdentity := newMockContext("Org2MSP")
+	clientIdentity.On("GetAttributeValue", CreatorAttribute).Return(CreatorAttributeValue, true, nil)
+	stub.On("GetState", compositeKey(configObjectType, configAuthorizedMSPs)).Return(nil, nil)
+	stub.On("GetState", compositeKey(configObjectType, configWorkflowGraph)).Return(nil, nil)
+
+	payload := mustMarshal(t, &VerificationRecord{ID: "REC001", Description: "Buyer contract terms", Status: "CREATED"})
+	stub.On("GetTransient").Return(map[string][]byte{"record": payload}, nil)
+	stub.On("GetState", "REC001").Return(nil, nil)
+	stub.On("GetTxTimestamp").Return(timestamppb.New(time.Date(2024, 3, 1, 8, 0, 0, 0, time.UTC)), nil)
+
+	var private VerificationRecord
+	stub.On("PutPrivateData", "collectionSensitive", "REC001", mock.Anything).Run(func(args mock.Arguments) {
+		_ = json.Unmarshal(args.Get(2).([]byte), &private)
+	}).Return(nil)
+	var public VerificationRecord
+	stub.On("PutState", "REC001", mock.Anything).Run(func(args mock.Arguments) {
+		_ = json.Unmarshal(args.Get(1).([]byte), &public)
+	}).Return(nil)
+	stub.On("PutState", compositeKey(statusIndex, "CREATED", "REC001"), indexEntryValue).Return(nil)
+	stub.On("PutState", compositeKey(partyIndex, "Org2MSP", "REC001"), indexEntryValue).Return(nil)
+
+	contract := new(HistoryContract)
+	err := contract.CreatePrivateRecord(ctx, "REC001")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "Buyer contract terms", private.Description)
+	assert.Equal(t, "Org2MSP", private.Party)
+	assert.Equal(t, "REC001", public.ID)
+	assert.Equal(t, "CREATED", public.Status)
+	assert.Empty(t, public.Description)
+	assert.Equal(t, "Org2MSP", public.Party)
+	assert.Equal(t, "2024-03-01T08:00:00Z", public.Timestamp)
+	assert.Equal(t, "2024-03-01T08:00:00Z", public.CreatedAt)
+	t.Log("Full record stored privately; the public stub only carries ID, owner, status and timestamps")
+}
+
+func TestCreatePrivateRecordMissingTransient(t *testing.T) {
+	ctx, stub, _ := newMockContext("Org2MSP")
+	stub.On("GetTransient").Return(map[string][]byte{}, nil)
+
+	contract := new(HistoryContract)
+	err := contract.CreatePrivateRecord(ctx, "REC001")
+
+	assert.EqualError(t, err, `transient map must contain the "record" entry`)
+	stub.AssertNotCalled(t, "PutPrivateData", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestCreatePrivateRecordValidatesPayload(t *testing.T) {
+	ctx, stub, _ := newMockContext("Org2MSP")
+	stub.On("GetState", compositeKey(configObjectType, configWorkflowGraph)).Return(nil, nil)
+	payload := mustMarshal(t, &VerificationRecord{ID: "REC001", Description: "Buyer contract terms", Status: "SHIPPED"})
+	stub.On("GetTransient").Return(map[string][]byte{"record": payload}, nil)
+
+	contract := new(HistoryContract)
+	err := contract.CreatePrivateRecord(ctx, "REC001")
+
+	assert.ErrorIs(t, err, ErrInvalidStatus)
+	stub.AssertNotCalled(t, "PutPrivateData", mock.Anything, mock.Anything, mock.Anything)
+	stub.AssertNotCalled(t, "PutState", mock.Anything, mock.Anything)
+}
+
+func TestGetPrivateRecord(t *testing.T) {
+	t.Log("Starting TestGetPrivateRecord: Verifying a record is read back from the collection")
+	ctx, stub, _ := newMockContext("Org2MSP")
+
+	stored := &VerificationRecord{ID: "REC001", Description: "Buyer contract terms", Party: "Org2MSP", Status: "CREATED"}
+	stub.On("GetPrivateData", "collectionSensitive", "REC001").Return(mustMarshal(t, stored), nil)
+
+	contract := new(HistoryContract)
+	record, err := contract.GetPrivateRecord(ctx, "REC001")
+
+	assert.NoError(t, err)
+	assert.Equal(t, stored, record)
+}
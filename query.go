@@ -0,0 +1,492 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// RecordsChunk is one slice of the ledger returned by GetRecordsChunk
+type RecordsChunk struct {
+	Records      []*VerificationRecord `json:"records"`
+	NextStartKey string                `json:"nextStartKey"` // Empty once the end of the ledger is reached
+}
+
+// PaginatedQueryResult is one page of records plus the bookmark to fetch the next page
+type PaginatedQueryResult struct {
+	Records             []*VerificationRecord `json:"records"`
+	FetchedRecordsCount int32                 `json:"fetchedRecordsCount"`
+	Bookmark            string                `json:"bookmark"` // Empty on the last page
+}
+
+// GetAllRecordsWithPagination returns one page of records. Pass an empty bookmark for the first page and the
+// returned bookmark for each following one; the last page comes back with an empty bookmark.
+// A pageSize of 0 uses the configured default page size. Soft-deleted records are left out of the page,
+// so a page may hold fewer records than FetchedRecordsCount.
+func (s *HistoryContract) GetAllRecordsWithPagination(ctx contractapi.TransactionContextInterface, pageSize int32, bookmark string) (*PaginatedQueryResult, error) {
+	pageSize, err := resolvePageSize(ctx, pageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	resultsIterator, responseMetadata, err := ctx.GetStub().GetStateByRangeWithPagination("", "", pageSize, bookmark)
+	if err != nil {
+		return nil, err
+	}
+	defer resultsIterator.Close()
+
+	result := &PaginatedQueryResult{Records: []*VerificationRecord{}}
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+
+		var record VerificationRecord
+		if err := json.Unmarshal(queryResponse.Value, &record); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal record %s: %v", queryResponse.Key, err)
+		}
+		if record.Deleted {
+			continue
+		}
+		result.Records = append(result.Records, &record)
+	}
+
+	if err := redactConfidential(ctx, result.Records...); err != nil {
+		return nil, err
+	}
+
+	if responseMetadata != nil {
+		result.FetchedRecordsCount = responseMetadata.FetchedRecordsCount
+		result.Bookmark = responseMetadata.Bookmark
+	}
+	// A short page means the range is exhausted, whatever bookmark the peer handed back
+	if result.FetchedRecordsCount < pageSize {
+		result.Bookmark = ""
+	}
+
+	return result, nil
+}
+
+// GetRecordsChunk returns up to chunkSize records starting at startKey (inclusive) together with the key
+// the next chunk starts from. Unlike CouchDB bookmarks this relies on plain range queries, so it works on LevelDB too.
+// A chunkSize of 0 uses the configured default page size. Soft-deleted records are skipped and do not count
+// toward the chunk size.
+func (s *HistoryContract) GetRecordsChunk(ctx contractapi.TransactionContextInterface, startKey string, chunkSize int32) (*RecordsChunk, error) {
+	chunkSize, err := resolvePageSize(ctx, chunkSize)
+	if err != nil {
+		return nil, err
+	}
+
+	resultsIterator, err := ctx.GetStub().GetStateByRange(startKey, "")
+	if err != nil {
+		return nil, err
+	}
+	defer resultsIterator.Close()
+
+	chunk := &RecordsChunk{Records: []*VerificationRecord{}}
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+
+		// One record past the chunk tells the client where to resume
+		if int32(len(chunk.Records)) == chunkSize {
+			chunk.NextStartKey = queryResponse.Key
+			break
+		}
+
+		var record VerificationRecord
+		if err := json.Unmarshal(queryResponse.Value, &record); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal record %s: %v", queryResponse.Key, err)
+		}
+		if record.Deleted {
+			continue
+		}
+		chunk.Records = append(chunk.Records, &record)
+	}
+
+	if err := redactConfidential(ctx, chunk.Records...); err != nil {
+		return nil, err
+	}
+
+	return chunk, nil
+}
+
+// GetAllRecords returns every record, masking confidential descriptions as in GetRecord.
+// Soft-deleted records are only included when includeDeleted is true.
+func (s *HistoryContract) GetAllRecords(ctx contractapi.TransactionContextInterface, includeDeleted bool) ([]*VerificationRecord, error) {
+	records, err := s.getAllRecords(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	listed := []*VerificationRecord{}
+	for _, record := range records {
+		if record.Deleted && !includeDeleted {
+			continue
+		}
+		listed = append(listed, record)
+	}
+
+	if err := redactConfidential(ctx, listed...); err != nil {
+		return nil, err
+	}
+	return listed, nil
+}
+
+// RecordSummary is the lightweight projection of a record used by listing views
+type RecordSummary struct {
+	ID        string `json:"id"`
+	Status    string `json:"status"`
+	Party     string `json:"party"`
+	Timestamp string `json:"timestamp"`
+}
+
+// GetAllRecordSummaries returns the ID, status, party and timestamp of every record that is not soft-deleted.
+// Descriptions and the other fields never leave the peer, which keeps dashboard payloads small;
+// use GetAllRecords when the full records are needed.
+func (s *HistoryContract) GetAllRecordSummaries(ctx contractapi.TransactionContextInterface) ([]RecordSummary, error) {
+	records, err := s.getAllRecords(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	summaries := []RecordSummary{}
+	for _, record := range records {
+		if record.Deleted {
+			continue
+		}
+		summaries = append(summaries, RecordSummary{
+			ID:        record.ID,
+			Status:    record.Status,
+			Party:     record.Party,
+			Timestamp: record.Timestamp,
+		})
+	}
+	return summaries, nil
+}
+
+// GetRecentRecords returns the n records with the most recent Timestamp, newest first, for a latest activity view.
+// World state is not ordered by time, so every record is read and sorted in memory. Soft-deleted records are
+// left out, and records whose Timestamp cannot be parsed sort last.
+func (s *HistoryContract) GetRecentRecords(ctx contractapi.TransactionContextInterface, n int) ([]*VerificationRecord, error) {
+	if n <= 0 {
+		return nil, fmt.Errorf("n must be positive, got %d", n)
+	}
+
+	records, err := s.getAllRecords(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	recent := []*VerificationRecord{}
+	updatedAt := make(map[string]time.Time, len(records))
+	for _, record := range records {
+		if record.Deleted {
+			continue
+		}
+		// A zero time is left for unparseable timestamps, which puts them at the end
+		updatedAt[record.ID], _ = time.Parse(time.RFC3339, record.Timestamp)
+		recent = append(recent, record)
+	}
+
+	sort.SliceStable(recent, func(i, j int) bool {
+		return updatedAt[recent[i].ID].After(updatedAt[recent[j].ID])
+	})
+	if len(recent) > n {
+		recent = recent[:n]
+	}
+
+	if err := redactConfidential(ctx, recent...); err != nil {
+		return nil, err
+	}
+	return recent, nil
+}
+
+// GetRecordsModifiedSince returns the records whose Timestamp is after since (RFC3339), for incremental sync.
+// Soft-deleted records are included so clients learn about the deletion; records whose Timestamp
+// cannot be parsed are skipped.
+func (s *HistoryContract) GetRecordsModifiedSince(ctx contractapi.TransactionContextInterface, since string) ([]*VerificationRecord, error) {
+	cutoff, err := time.Parse(time.RFC3339, since)
+	if err != nil {
+		return nil, fmt.Errorf("invalid since time %q: %v", since, err)
+	}
+
+	records, err := s.getAllRecords(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	modified := []*VerificationRecord{}
+	for _, record := range records {
+		updatedAt, err := time.Parse(time.RFC3339, record.Timestamp)
+		if err != nil || !updatedAt.After(cutoff) {
+			continue
+		}
+		modified = append(modified, record)
+	}
+
+	if err := redactConfidential(ctx, modified...); err != nil {
+		return nil, err
+	}
+	return modified, nil
+}
+
+// GetRecordsNeedingAttention returns the records in a status whose Timestamp is more than olderThanSeconds
+// before the transaction timestamp, e.g. records stuck in PENDING past their SLA. Using the transaction
+// time instead of the wall clock keeps every endorsing peer's answer identical. Soft-deleted records and
+// records whose Timestamp cannot be parsed are left out.
+func (s *HistoryContract) GetRecordsNeedingAttention(ctx contractapi.TransactionContextInterface, status string, olderThanSeconds int64) ([]*VerificationRecord, error) {
+	if olderThanSeconds <= 0 {
+		return nil, fmt.Errorf("olderThanSeconds must be positive, got %d", olderThanSeconds)
+	}
+
+	txTimestamp, err := ctx.GetStub().GetTxTimestamp()
+	if err != nil {
+		return nil, err
+	}
+	cutoff := txTimestamp.AsTime().Add(-time.Duration(olderThanSeconds) * time.Second)
+
+	records, err := s.recordsByStatusIndex(ctx, status)
+	if err != nil {
+		return nil, err
+	}
+
+	stale := []*VerificationRecord{}
+	for _, record := range records {
+		if record.Deleted {
+			continue
+		}
+		updatedAt, err := time.Parse(time.RFC3339, record.Timestamp)
+		if err != nil || !updatedAt.Before(cutoff) {
+			continue
+		}
+		stale = append(stale, record)
+	}
+
+	if err := redactConfidential(ctx, stale...); err != nil {
+		return nil, err
+	}
+	return stale, nil
+}
+
+// GetAllRecordsAsMap returns every record keyed by its ID for clients that need direct lookups.
+// World state keys are unique so collisions should not happen; if two entries share an ID the last one wins.
+// Soft-deleted records are left out, as in GetAllRecords.
+func (s *HistoryContract) GetAllRecordsAsMap(ctx contractapi.TransactionContextInterface) (map[string]*VerificationRecord, error) {
+	records, err := s.getAllRecords(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := redactConfidential(ctx, records...); err != nil {
+		return nil, err
+	}
+
+	recordMap := make(map[string]*VerificationRecord, len(records))
+	for _, record := range records {
+		if record.Deleted {
+			continue
+		}
+		recordMap[record.ID] = record
+	}
+
+	return recordMap, nil
+}
+
+// GetRecordsByBatch returns every record stamped with the given import batch ID.
+// This uses a rich query and therefore requires the CouchDB state database.
+func (s *HistoryContract) GetRecordsByBatch(ctx contractapi.TransactionContextInterface, batchID string) ([]*VerificationRecord, error) {
+	if batchID == "" {
+		return nil, fmt.Errorf("batch ID must not be empty")
+	}
+
+	return s.queryVisibleRecords(ctx, map[string]interface{}{"batchId": batchID})
+}
+
+// QueryRecordsByStatus returns every record currently in the given status, e.g. all REJECTED records.
+// This uses a rich query and therefore requires the CouchDB state database; it fails on LevelDB.
+func (s *HistoryContract) QueryRecordsByStatus(ctx contractapi.TransactionContextInterface, status string) ([]*VerificationRecord, error) {
+	if status == "" {
+		return nil, fmt.Errorf("status must not be empty")
+	}
+	if err := requireKnownStatus(ctx, status); err != nil {
+		return nil, err
+	}
+
+	return s.queryVisibleRecords(ctx, map[string]interface{}{"status": status})
+}
+
+// QueryRecordsByStatusAndParty returns the records matching both a status and an owning party,
+// e.g. all PENDING records owned by Org2MSP. An empty status or party matches any value, but not both.
+// Like QueryRecordsByStatus it requires the CouchDB state database.
+func (s *HistoryContract) QueryRecordsByStatusAndParty(ctx contractapi.TransactionContextInterface, status string, party string) ([]*VerificationRecord, error) {
+	selector := map[string]interface{}{}
+	if status != "" {
+		if err := requireKnownStatus(ctx, status); err != nil {
+			return nil, err
+		}
+		selector["status"] = status
+	}
+	if party != "" {
+		selector["party"] = party
+	}
+	if len(selector) == 0 {
+		return nil, fmt.Errorf("status and party must not both be empty")
+	}
+
+	return s.queryVisibleRecords(ctx, selector)
+}
+
+// QueryRecordsByMetadata returns every record whose metadata maps key to value, e.g. location=Nairobi.
+// Like QueryRecordsByStatus it requires the CouchDB state database.
+func (s *HistoryContract) QueryRecordsByMetadata(ctx contractapi.TransactionContextInterface, key string, value string) ([]*VerificationRecord, error) {
+	if key == "" {
+		return nil, fmt.Errorf("metadata key must not be empty")
+	}
+
+	return s.queryVisibleRecords(ctx, map[string]interface{}{"metadata." + key: value})
+}
+
+// QueryRecordsByCategory returns every record in the given category, e.g. SHIPMENT.
+// Like QueryRecordsByStatus it requires the CouchDB state database.
+func (s *HistoryContract) QueryRecordsByCategory(ctx contractapi.TransactionContextInterface, category string) ([]*VerificationRecord, error) {
+	if category == "" {
+		return nil, fmt.Errorf("category must not be empty")
+	}
+	if !AllowedCategories[category] {
+		return nil, fmt.Errorf("unknown category %q", category)
+	}
+
+	return s.queryVisibleRecords(ctx, map[string]interface{}{"category": category})
+}
+
+// QueryRecordIDs runs a CouchDB selector (e.g. {"status":"PENDING"}) and returns only the matching keys.
+// Values are never unmarshalled, which keeps the response small for clients that only need the key set.
+// Composite keys (tombstones, index and config entries) matching the selector are left out.
+func (s *HistoryContract) QueryRecordIDs(ctx contractapi.TransactionContextInterface, selectorJSON string) ([]string, error) {
+	selector, err := parseSelector(selectorJSON)
+	if err != nil {
+		return nil, err
+	}
+
+	queryString, err := buildQueryString(selector)
+	if err != nil {
+		return nil, err
+	}
+
+	resultsIterator, err := ctx.GetStub().GetQueryResult(queryString)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute rich query: %v", err)
+	}
+	defer resultsIterator.Close()
+
+	ids := []string{}
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+		if strings.HasPrefix(queryResponse.Key, compositeKeyNamespace) {
+			continue
+		}
+		ids = append(ids, queryResponse.Key)
+	}
+
+	return ids, nil
+}
+
+// QueryRecordsByParty returns every record owned by the given organization (MSP ID).
+// Like QueryRecordsByStatus it requires the CouchDB state database.
+func (s *HistoryContract) QueryRecordsByParty(ctx contractapi.TransactionContextInterface, party string) ([]*VerificationRecord, error) {
+	if party == "" {
+		return nil, fmt.Errorf("party must not be empty")
+	}
+
+	return s.queryVisibleRecords(ctx, map[string]interface{}{"party": party})
+}
+
+// queryRecords runs a CouchDB selector query and drains the results into records without masking.
+// The query string is built with json.Marshal so values can never break out of the selector.
+// Matches under composite keys, such as tombstones carrying the same id, are not records and are skipped.
+func (s *HistoryContract) queryRecords(ctx contractapi.TransactionContextInterface, selector map[string]interface{}) ([]*VerificationRecord, error) {
+	queryString, err := buildQueryString(selector)
+	if err != nil {
+		return nil, err
+	}
+
+	resultsIterator, err := ctx.GetStub().GetQueryResult(queryString)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute rich query: %v", err)
+	}
+	defer resultsIterator.Close()
+
+	var records []*VerificationRecord
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+		if strings.HasPrefix(queryResponse.Key, compositeKeyNamespace) {
+			continue
+		}
+
+		var record VerificationRecord
+		if err := json.Unmarshal(queryResponse.Value, &record); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal record %s: %v", queryResponse.Key, err)
+		}
+		records = append(records, &record)
+	}
+
+	return records, nil
+}
+
+// queryVisibleRecords runs a selector query for a listing: soft-deleted records are left out and
+// confidential records are masked for the caller
+func (s *HistoryContract) queryVisibleRecords(ctx contractapi.TransactionContextInterface, selector map[string]interface{}) ([]*VerificationRecord, error) {
+	records, err := s.queryRecords(ctx, selector)
+	if err != nil {
+		return nil, err
+	}
+
+	listed := []*VerificationRecord{}
+	for _, record := range records {
+		if record.Deleted {
+			continue
+		}
+		listed = append(listed, record)
+	}
+
+	if err := redactConfidential(ctx, listed...); err != nil {
+		return nil, err
+	}
+
+	return listed, nil
+}
+
+// buildQueryString wraps a selector into a CouchDB query document
+func buildQueryString(selector map[string]interface{}) (string, error) {
+	query, err := json.Marshal(map[string]interface{}{"selector": selector})
+	if err != nil {
+		return "", fmt.Errorf("failed to build query: %v", err)
+	}
+	return string(query), nil
+}
+
+// parseSelector decodes a client supplied CouchDB selector, which must be a non-empty JSON object
+func parseSelector(selectorJSON string) (map[string]interface{}, error) {
+	var selector map[string]interface{}
+	if err := json.Unmarshal([]byte(selectorJSON), &selector); err != nil {
+		return nil, fmt.Errorf("selector must be a JSON object: %v", err)
+	}
+	if len(selector) == 0 {
+		return nil, fmt.Errorf("selector must not be empty")
+	}
+	return selector, nil
+}
@@ -0,0 +1,485 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-protos-go/ledger/queryresult"
+	"github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestBatchImportStampsBatchIDAndQueriesBack(t *testing.T) {
+	t.Log("Starting TestBatchImportStampsBatchIDAndQueriesBack: Verifying imported records carry their batch ID")
+	ctx, stub, clientIdentity := newMockContext("Org1MSP")
+	clientIdentity.On("GetAttributeValue", CreatorAttribute).Return(CreatorAttributeValue, true, nil)
+
+	var written []VerificationRecord
+	stub.On("GetState", mock.Anything).Return(nil, nil)
+	stub.On("PutState", mock.Anything, indexEntryValue).Return(nil)
+	stub.On("PutState", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		var record VerificationRecord
+		_ = json.Unmarshal(args.Get(1).([]byte), &record)
+		written = append(written, record)
+	}).Return(nil)
+
+	t.Log("Importing two records under batch B42...")
+	contract := new(HistoryContract)
+	_, err := contract.BatchImport(ctx, `[{"id":"IMP001","description":"Imported record","status":"CREATED"},{"id":"IMP002","description":"Imported record","status":"CREATED"}]`, "B42", BatchModeAbort)
+
+	assert.NoError(t, err)
+	assert.Len(t, written, 2)
+	for _, record := range written {
+		assert.Equal(t, "B42", record.BatchID)
+	}
+
+	t.Log("Querying the batch back by its ID...")
+	stub.On("GetQueryResult", `{"selector":{"batchId":"B42"}}`).Return(newStateIterator(t, written...), nil)
+	records, err := contract.GetRecordsByBatch(ctx, "B42")
+
+	assert.NoError(t, err)
+	assert.Len(t, records, 2)
+	assert.Equal(t, "IMP001", records[0].ID)
+	assert.Equal(t, "IMP002", records[1].ID)
+	t.Log("Both records were returned for batch B42")
+}
+
+func TestGetRecordsChunkReadsLedgerInTwoChunks(t *testing.T) {
+	t.Log("Starting TestGetRecordsChunkReadsLedgerInTwoChunks: Verifying manual chunked iteration")
+	ctx, stub, _ := newMockContext("Org1MSP")
+
+	first := VerificationRecord{ID: "REC001", Status: "CREATED"}
+	second := VerificationRecord{ID: "REC002", Status: "PENDING"}
+	third := VerificationRecord{ID: "REC003", Status: "VERIFIED"}
+	stub.On("GetStateByRange", "", "").Return(newStateIterator(t, first, second, third), nil)
+	stub.On("GetStateByRange", "REC003", "").Return(newStateIterator(t, third), nil)
+
+	contract := new(HistoryContract)
+
+	t.Log("Reading the first chunk...")
+	chunk, err := contract.GetRecordsChunk(ctx, "", 2)
+	assert.NoError(t, err)
+	assert.Len(t, chunk.Records, 2)
+	assert.Equal(t, "REC003", chunk.NextStartKey)
+
+	t.Log("Reading the second chunk from the returned start key...")
+	chunk, err = contract.GetRecordsChunk(ctx, chunk.NextStartKey, 2)
+	assert.NoError(t, err)
+	assert.Len(t, chunk.Records, 1)
+	assert.Equal(t, "REC003", chunk.Records[0].ID)
+	assert.Empty(t, chunk.NextStartKey)
+	t.Log("The ledger was read completely in two chunks")
+}
+
+func TestGetAllRecordsAsMap(t *testing.T) {
+	t.Log("Starting TestGetAllRecordsAsMap: Verifying records are keyed by ID")
+	ctx, stub, _ := newMockContext("Org1MSP")
+
+	records := []VerificationRecord{
+		{ID: "REC001", Description: "Contract", Status: "CREATED"},
+		{ID: "REC002", Description: "Manifest", Status: "PENDING"},
+	}
+	stub.On("GetStateByRange", "", "").Return(newStateIterator(t, records...), nil)
+
+	contract := new(HistoryContract)
+	recordMap, err := contract.GetAllRecordsAsMap(ctx)
+
+	assert.NoError(t, err)
+	assert.Len(t, recordMap, 2)
+	assert.Equal(t, "Contract", recordMap["REC001"].Description)
+	assert.Equal(t, "Manifest", recordMap["REC002"].Description)
+}
+
+func TestGetAllRecordSummaries(t *testing.T) {
+	t.Log("Starting TestGetAllRecordSummaries: Verifying summaries carry only the listing fields")
+	ctx, stub, _ := newMockContext("Org1MSP")
+
+	records := []VerificationRecord{
+		{ID: "REC001", Description: "Contract", Party: "Org1MSP", Status: "CREATED", Timestamp: "2024-03-10T09:00:00Z"},
+		{ID: "REC002", Description: "Manifest", Party: "Org2MSP", Status: "PENDING", Deleted: true},
+	}
+	stub.On("GetStateByRange", "", "").Return(newStateIterator(t, records...), nil)
+
+	contract := new(HistoryContract)
+	summaries, err := contract.GetAllRecordSummaries(ctx)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []RecordSummary{{ID: "REC001", Status: "CREATED", Party: "Org1MSP", Timestamp: "2024-03-10T09:00:00Z"}}, summaries)
+	summaryJSON, err := json.Marshal(summaries)
+	assert.NoError(t, err)
+	assert.NotContains(t, string(summaryJSON), "Contract")
+	assert.NotContains(t, string(summaryJSON), "description")
+	t.Log("The description was dropped and the deleted record left out")
+}
+
+func TestQueryRecordIDs(t *testing.T) {
+	t.Log("Starting TestQueryRecordIDs: Verifying only matching keys are returned")
+	ctx, stub, _ := newMockContext("Org1MSP")
+
+	iterator := newKVIterator(
+		&queryresult.KV{Key: "REC001", Value: []byte("not even parsed")},
+		&queryresult.KV{Key: "REC004"},
+		&queryresult.KV{Key: compositeKey(tombstoneObjectType, "REC009")},
+	)
+	stub.On("GetQueryResult", `{"selector":{"status":"PENDING"}}`).Return(iterator, nil)
+
+	contract := new(HistoryContract)
+	ids, err := contract.QueryRecordIDs(ctx, `{"status":"PENDING"}`)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"REC001", "REC004"}, ids)
+}
+
+func TestQueryRecordsSkipsCompositeKeys(t *testing.T) {
+	ctx, stub, _ := newMockContext("Org1MSP")
+
+	// A tombstone carries the record's id, so an id selector matches it as well
+	tombstone := Tombstone{ID: "REC009", DeletedBy: "Org1MSP", Reason: "duplicate", LastStatus: "CREATED"}
+	iterator := newKVIterator(
+		&queryresult.KV{Key: "REC009", Value: mustMarshal(t, VerificationRecord{ID: "REC009", Status: "CREATED"})},
+		&queryresult.KV{Key: compositeKey(tombstoneObjectType, "REC009"), Value: mustMarshal(t, tombstone)},
+	)
+	stub.On("GetQueryResult", `{"selector":{"id":"REC009"}}`).Return(iterator, nil)
+
+	contract := new(HistoryContract)
+	records, err := contract.queryRecords(ctx, map[string]interface{}{"id": "REC009"})
+
+	assert.NoError(t, err)
+	assert.Len(t, records, 1)
+	assert.Equal(t, "REC009", records[0].ID)
+	assert.Equal(t, "CREATED", records[0].Status)
+}
+
+func TestQueryRecordIDsRejectsInvalidSelector(t *testing.T) {
+	ctx, _, _ := newMockContext("Org1MSP")
+
+	contract := new(HistoryContract)
+	_, err := contract.QueryRecordIDs(ctx, `["status"]`)
+	assert.Error(t, err)
+
+	_, err = contract.QueryRecordIDs(ctx, `{}`)
+	assert.EqualError(t, err, "selector must not be empty")
+}
+
+func TestQueryRecordsByStatus(t *testing.T) {
+	t.Log("Starting TestQueryRecordsByStatus: Verifying the status selector is sent to CouchDB")
+	ctx, stub, _ := newMockContext("Org1MSP")
+	stub.On("GetState", compositeKey(configObjectType, configWorkflowGraph)).Return(nil, nil)
+
+	rejected := []VerificationRecord{
+		{ID: "REC002", Party: "Org2MSP", Status: "REJECTED"},
+		{ID: "REC005", Party: "Org3MSP", Status: "REJECTED"},
+	}
+	stub.On("GetQueryResult", `{"selector":{"status":"REJECTED"}}`).Return(newStateIterator(t, rejected...), nil)
+
+	contract := new(HistoryContract)
+	records, err := contract.QueryRecordsByStatus(ctx, "REJECTED")
+
+	assert.NoError(t, err)
+	assert.Len(t, records, 2)
+	assert.Equal(t, "REC002", records[0].ID)
+	assert.Equal(t, "REC005", records[1].ID)
+	stub.AssertExpectations(t)
+}
+
+func TestQueryRecordsByParty(t *testing.T) {
+	t.Log("Starting TestQueryRecordsByParty: Verifying only the requested party's records are returned")
+	ctx, stub, _ := newMockContext("Org2MSP")
+
+	org1Record := VerificationRecord{ID: "REC001", Party: "Org1MSP", Status: "CREATED"}
+	org2Record := VerificationRecord{ID: "REC002", Party: "Org2MSP", Status: "PENDING"}
+	stub.On("GetQueryResult", `{"selector":{"party":"Org1MSP"}}`).Return(newStateIterator(t, org1Record), nil)
+	stub.On("GetQueryResult", `{"selector":{"party":"Org2MSP"}}`).Return(newStateIterator(t, org2Record), nil)
+
+	contract := new(HistoryContract)
+	records, err := contract.QueryRecordsByParty(ctx, "Org2MSP")
+
+	assert.NoError(t, err)
+	assert.Len(t, records, 1)
+	assert.Equal(t, "REC002", records[0].ID)
+	stub.AssertNotCalled(t, "GetQueryResult", `{"selector":{"party":"Org1MSP"}}`)
+	t.Log("Only Org2MSP's record was returned")
+}
+
+func TestSoftDeletedRecordsHiddenFromListings(t *testing.T) {
+	t.Log("Starting TestSoftDeletedRecordsHiddenFromListings: Verifying listings and counts agree on soft-deleted records")
+	ctx, stub, _ := newMockContext("Org1MSP")
+	stub.On("GetState", compositeKey(configObjectType, configWorkflowGraph)).Return(nil, nil)
+
+	live := VerificationRecord{ID: "REC001", Party: "Org1MSP", Status: "PENDING"}
+	deleted := VerificationRecord{ID: "REC002", Party: "Org1MSP", Status: "PENDING", Deleted: true}
+	stub.On("GetState", "REC001").Return(mustMarshal(t, live), nil)
+	stub.On("GetState", "REC002").Return(mustMarshal(t, deleted), nil)
+
+	stub.On("GetQueryResult", `{"selector":{"status":"PENDING"}}`).Return(newStateIterator(t, live, deleted), nil)
+	stub.On("GetQueryResult", `{"selector":{"party":"Org1MSP"}}`).Return(newStateIterator(t, live, deleted), nil)
+	stub.On("GetStateByPartialCompositeKey", partyIndex, []string{"Org1MSP"}).Return(newKVIterator(
+		&queryresult.KV{Key: compositeKey(partyIndex, "Org1MSP", "REC001"), Value: indexEntryValue},
+		&queryresult.KV{Key: compositeKey(partyIndex, "Org1MSP", "REC002"), Value: indexEntryValue},
+	), nil)
+	stub.On("GetStateByPartialCompositeKey", statusIndex, []string{"PENDING"}).Return(newKVIterator(
+		&queryresult.KV{Key: compositeKey(statusIndex, "PENDING", "REC001"), Value: indexEntryValue},
+		&queryresult.KV{Key: compositeKey(statusIndex, "PENDING", "REC002"), Value: indexEntryValue},
+	), nil)
+	stub.On("GetStateByRangeWithPagination", "", "", int32(10), "").
+		Return(newStateIterator(t, live, deleted), &peer.QueryResponseMetadata{FetchedRecordsCount: 2}, nil)
+	// The full range is scanned four times; each scan needs its own iterator
+	for i := 0; i < 4; i++ {
+		stub.On("GetStateByRange", "", "").Return(newStateIterator(t, live, deleted), nil).Once()
+	}
+
+	contract := new(HistoryContract)
+	listings := map[string]func() ([]*VerificationRecord, error){
+		"QueryRecordsByStatus": func() ([]*VerificationRecord, error) {
+			return contract.QueryRecordsByStatus(ctx, "PENDING")
+		},
+		"QueryRecordsByParty": func() ([]*VerificationRecord, error) {
+			return contract.QueryRecordsByParty(ctx, "Org1MSP")
+		},
+		"QueryRecordsByPartyIndexed": func() ([]*VerificationRecord, error) {
+			return contract.QueryRecordsByPartyIndexed(ctx, "Org1MSP")
+		},
+		"GetAllRecordsWithPagination": func() ([]*VerificationRecord, error) {
+			page, err := contract.GetAllRecordsWithPagination(ctx, 10, "")
+			if err != nil {
+				return nil, err
+			}
+			return page.Records, nil
+		},
+		"GetRecordsChunk": func() ([]*VerificationRecord, error) {
+			chunk, err := contract.GetRecordsChunk(ctx, "", 10)
+			if err != nil {
+				return nil, err
+			}
+			return chunk.Records, nil
+		},
+	}
+	for name, list := range listings {
+		records, err := list()
+		assert.NoError(t, err, name)
+		assert.Len(t, records, 1, name)
+		assert.Equal(t, "REC001", records[0].ID, name)
+	}
+
+	recordMap, err := contract.GetAllRecordsAsMap(ctx)
+	assert.NoError(t, err)
+	assert.Contains(t, recordMap, "REC001")
+	assert.NotContains(t, recordMap, "REC002")
+
+	count, err := contract.CountRecordsByStatus(ctx, "PENDING")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, count)
+
+	stats, err := contract.GetLedgerStats(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]int{"PENDING": 1}, stats.ByStatus)
+	assert.Equal(t, 1, stats.DeletedRecords)
+
+	all, err := contract.GetAllRecords(ctx, false)
+	assert.NoError(t, err)
+	assert.Len(t, all, 1)
+	t.Log("Every listing and count left the soft-deleted record out")
+}
+
+func TestGetAllRecordsWithPagination(t *testing.T) {
+	t.Log("Starting TestGetAllRecordsWithPagination: Verifying page metadata is populated from the peer response")
+	ctx, stub, _ := newMockContext("Org1MSP")
+
+	firstPage := newStateIterator(t, VerificationRecord{ID: "REC001"}, VerificationRecord{ID: "REC002"})
+	stub.On("GetStateByRangeWithPagination", "", "", int32(2), "").
+		Return(firstPage, &peer.QueryResponseMetadata{FetchedRecordsCount: 2, Bookmark: "REC003"}, nil)
+	lastPage := newStateIterator(t, VerificationRecord{ID: "REC003"})
+	stub.On("GetStateByRangeWithPagination", "", "", int32(2), "REC003").
+		Return(lastPage, &peer.QueryResponseMetadata{FetchedRecordsCount: 1, Bookmark: "REC003"}, nil)
+
+	contract := new(HistoryContract)
+
+	t.Log("Fetching the first page with an empty bookmark...")
+	page, err := contract.GetAllRecordsWithPagination(ctx, 2, "")
+	assert.NoError(t, err)
+	assert.Len(t, page.Records, 2)
+	assert.Equal(t, int32(2), page.FetchedRecordsCount)
+	assert.Equal(t, "REC003", page.Bookmark)
+
+	t.Log("Fetching the last page with the returned bookmark...")
+	page, err = contract.GetAllRecordsWithPagination(ctx, 2, page.Bookmark)
+	assert.NoError(t, err)
+	assert.Len(t, page.Records, 1)
+	assert.Equal(t, int32(1), page.FetchedRecordsCount)
+	assert.Empty(t, page.Bookmark)
+	t.Log("The last page returned an empty bookmark")
+}
+
+func TestGetRecentRecords(t *testing.T) {
+	t.Log("Starting TestGetRecentRecords: Verifying the newest records are returned first")
+	ctx, stub, _ := newMockContext("Org1MSP")
+
+	stub.On("GetStateByRange", "", "").Return(newStateIterator(t,
+		VerificationRecord{ID: "REC001", Status: "CREATED", Timestamp: "2024-03-01T08:00:00Z"},
+		VerificationRecord{ID: "REC002", Status: "PENDING", Timestamp: "2024-03-04T08:00:00Z"},
+		VerificationRecord{ID: "REC003", Status: "VERIFIED", Timestamp: "2024-03-02T08:00:00Z"},
+		VerificationRecord{ID: "REC004", Status: "PENDING", Timestamp: "2024-03-05T08:00:00Z", Deleted: true},
+		VerificationRecord{ID: "REC005", Status: "CREATED", Timestamp: "2024-03-03T08:00:00Z"},
+	), nil)
+
+	contract := new(HistoryContract)
+	records, err := contract.GetRecentRecords(ctx, 3)
+
+	assert.NoError(t, err)
+	var ids []string
+	for _, record := range records {
+		ids = append(ids, record.ID)
+	}
+	assert.Equal(t, []string{"REC002", "REC005", "REC003"}, ids)
+
+	_, err = contract.GetRecentRecords(ctx, 0)
+	assert.EqualError(t, err, "n must be positive, got 0")
+	t.Log("The three most recent live records came back newest first")
+}
+
+func TestQueryRecordsByMetadata(t *testing.T) {
+	t.Log("Starting TestQueryRecordsByMetadata: Verifying the selector targets the metadata key")
+	ctx, stub, _ := newMockContext("Org1MSP")
+
+	match := VerificationRecord{ID: "REC001", Party: "Org1MSP", Status: "CREATED", Metadata: map[string]string{"location": "Nairobi"}}
+	stub.On("GetQueryResult", `{"selector":{"metadata.location":"Nairobi"}}`).Return(newStateIterator(t, match), nil)
+
+	contract := new(HistoryContract)
+	records, err := contract.QueryRecordsByMetadata(ctx, "location", "Nairobi")
+
+	assert.NoError(t, err)
+	assert.Len(t, records, 1)
+	assert.Equal(t, "REC001", records[0].ID)
+
+	_, err = contract.QueryRecordsByMetadata(ctx, "", "Nairobi")
+	assert.EqualError(t, err, "metadata key must not be empty")
+	t.Log("Only the record tagged with location Nairobi was returned")
+}
+
+func TestQueryRecordsByCategory(t *testing.T) {
+	t.Log("Starting TestQueryRecordsByCategory: Verifying the selector filters on the category field")
+	ctx, stub, _ := newMockContext("Org1MSP")
+
+	shipment := VerificationRecord{ID: "REC002", Party: "Org1MSP", Status: "PENDING", Category: CategoryShipment}
+	stub.On("GetQueryResult", `{"selector":{"category":"SHIPMENT"}}`).Return(newStateIterator(t, shipment), nil)
+
+	contract := new(HistoryContract)
+	records, err := contract.QueryRecordsByCategory(ctx, CategoryShipment)
+
+	assert.NoError(t, err)
+	assert.Len(t, records, 1)
+	assert.Equal(t, CategoryShipment, records[0].Category)
+
+	_, err = contract.QueryRecordsByCategory(ctx, "FURNITURE")
+	assert.EqualError(t, err, `unknown category "FURNITURE"`)
+	t.Log("Only the SHIPMENT record was returned")
+}
+
+func TestQueryRecordsByStatusAndParty(t *testing.T) {
+	t.Log("Starting TestQueryRecordsByStatusAndParty: Verifying both fields end up in one selector")
+	ctx, stub, _ := newMockContext("Org1MSP")
+	stub.On("GetState", compositeKey(configObjectType, configWorkflowGraph)).Return(nil, nil)
+
+	// CouchDB evaluates the selector; the mock hands back the intersection it would return
+	// out of REC001 (Org2MSP, PENDING), REC002 (Org2MSP, VERIFIED) and REC003 (Org3MSP, PENDING)
+	intersection := VerificationRecord{ID: "REC001", Party: "Org2MSP", Status: "PENDING"}
+	pending := []VerificationRecord{intersection, {ID: "REC003", Party: "Org3MSP", Status: "PENDING"}}
+	stub.On("GetQueryResult", `{"selector":{"party":"Org2MSP","status":"PENDING"}}`).Return(newStateIterator(t, intersection), nil)
+	stub.On("GetQueryResult", `{"selector":{"status":"PENDING"}}`).Return(newStateIterator(t, pending...), nil)
+
+	contract := new(HistoryContract)
+	records, err := contract.QueryRecordsByStatusAndParty(ctx, "PENDING", "Org2MSP")
+	assert.NoError(t, err)
+	assert.Len(t, records, 1)
+	assert.Equal(t, "REC001", records[0].ID)
+
+	// An empty party leaves the party out of the selector
+	records, err = contract.QueryRecordsByStatusAndParty(ctx, "PENDING", "")
+	assert.NoError(t, err)
+	assert.Len(t, records, 2)
+
+	_, err = contract.QueryRecordsByStatusAndParty(ctx, "", "")
+	assert.EqualError(t, err, "status and party must not both be empty")
+	t.Log("Only the PENDING record owned by Org2MSP matched both fields")
+}
+
+func TestGetRecordsModifiedSince(t *testing.T) {
+	t.Log("Starting TestGetRecordsModifiedSince: Verifying only records changed after the cutoff are returned")
+	ctx, stub, _ := newMockContext("Org1MSP")
+
+	stub.On("GetStateByRange", "", "").Return(newStateIterator(t,
+		VerificationRecord{ID: "REC001", Status: "CREATED", Timestamp: "2024-03-01T08:00:00Z"},
+		VerificationRecord{ID: "REC002", Status: "PENDING", Timestamp: "2024-03-03T08:00:00Z"},
+	), nil)
+
+	contract := new(HistoryContract)
+	records, err := contract.GetRecordsModifiedSince(ctx, "2024-03-02T00:00:00Z")
+
+	assert.NoError(t, err)
+	assert.Len(t, records, 1)
+	assert.Equal(t, "REC002", records[0].ID)
+
+	_, err = contract.GetRecordsModifiedSince(ctx, "yesterday")
+	assert.Error(t, err)
+	t.Log("The record modified before the cutoff was skipped")
+}
+
+func TestMetadataWritesShowUpAsModified(t *testing.T) {
+	t.Log("Starting TestMetadataWritesShowUpAsModified: Verifying confidentiality and approver changes refresh the timestamp")
+	contract := new(HistoryContract)
+	writers := map[string]func(ctx *MockTransactionContext) error{
+		"SetRecordConfidential": func(ctx *MockTransactionContext) error {
+			return contract.SetRecordConfidential(ctx, "REC001", true)
+		},
+		"SetRequiredApprovers": func(ctx *MockTransactionContext) error {
+			return contract.SetRequiredApprovers(ctx, "REC001", []string{"Org3MSP"})
+		},
+	}
+
+	for name, write := range writers {
+		ctx, stub, _ := newMockContext("Org1MSP")
+		stub.On("GetState", "REC001").Return(mustMarshal(t, VerificationRecord{ID: "REC001", Party: "Org1MSP", Status: "PENDING", Timestamp: "2024-03-01T08:00:00Z"}), nil)
+		stub.On("GetTxTimestamp").Return(timestamppb.New(time.Date(2024, 3, 5, 8, 0, 0, 0, time.UTC)), nil)
+		var written VerificationRecord
+		stub.On("PutState", "REC001", mock.Anything).Run(func(args mock.Arguments) {
+			_ = json.Unmarshal(args.Get(1).([]byte), &written)
+		}).Return(nil)
+
+		assert.NoError(t, write(ctx), name)
+		assert.Equal(t, "2024-03-05T08:00:00Z", written.Timestamp, name)
+
+		// An incremental sync from before the change must pick the record up
+		syncCtx, syncStub, _ := newMockContext("Org1MSP")
+		syncStub.On("GetStateByRange", "", "").Return(newStateIterator(t, written), nil)
+		records, err := contract.GetRecordsModifiedSince(syncCtx, "2024-03-02T00:00:00Z")
+		assert.NoError(t, err, name)
+		assert.Len(t, records, 1, name)
+	}
+	t.Log("Both writers stamped the transaction time, so the change was picked up by the sync")
+}
+
+func TestGetRecordsNeedingAttention(t *testing.T) {
+	t.Log("Starting TestGetRecordsNeedingAttention: Verifying only records idle past the cutoff are returned")
+	ctx, stub, _ := newMockContext("Org1MSP")
+	stub.On("GetState", compositeKey(configObjectType, configWorkflowGraph)).Return(nil, nil)
+
+	// The transaction runs at noon; with a one day cutoff only the record idle since two days ago is stale
+	stub.On("GetTxTimestamp").Return(timestamppb.New(time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)), nil)
+	stub.On("GetStateByPartialCompositeKey", statusIndex, []string{"PENDING"}).Return(newKVIterator(
+		&queryresult.KV{Key: compositeKey(statusIndex, "PENDING", "REC001"), Value: indexEntryValue},
+		&queryresult.KV{Key: compositeKey(statusIndex, "PENDING", "REC002"), Value: indexEntryValue},
+	), nil)
+	stub.On("GetState", "REC001").Return(mustMarshal(t, VerificationRecord{ID: "REC001", Status: "PENDING", Timestamp: "2024-03-10T09:00:00Z"}), nil)
+	stub.On("GetState", "REC002").Return(mustMarshal(t, VerificationRecord{ID: "REC002", Status: "PENDING", Timestamp: "2024-03-08T12:00:00Z"}), nil)
+
+	contract := new(HistoryContract)
+	records, err := contract.GetRecordsNeedingAttention(ctx, "PENDING", 24*60*60)
+
+	assert.NoError(t, err)
+	assert.Len(t, records, 1)
+	assert.Equal(t, "REC002", records[0].ID)
+	t.Log("The fresh record was skipped and the stale one reported")
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// SealRecord finalizes a record so it can never change or be deleted again.
+// Only the owning party or the admin MSP may seal it.
+func (s *HistoryContract) SealRecord(ctx contractapi.TransactionContextInterface, id string) error {
+	record, err := s.readRecord(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := ensureMutable(record); err != nil {
+		return err
+	}
+
+	if _, err := authorizeOwner(ctx, record, "seal"); err != nil {
+		return err
+	}
+
+	timestamp, err := txTimestampString(ctx)
+	if err != nil {
+		return err
+	}
+	record.Sealed = true
+	record.Timestamp = timestamp
+	return s.putRecord(ctx, record)
+}
+
+// ensureMutable refuses any change to a sealed or locked record
+func ensureMutable(record *VerificationRecord) error {
+	if err := ensureNotSealed(record); err != nil {
+		return err
+	}
+	if record.Locked {
+		return fmt.Errorf("record %s is locked and must be unlocked before it can change", record.ID)
+	}
+	return nil
+}
+
+// ensureNotSealed refuses any change to a sealed record
+func ensureNotSealed(record *VerificationRecord) error {
+	if record.Sealed {
+		return fmt.Errorf("record %s is sealed", record.ID)
+	}
+	return nil
+}
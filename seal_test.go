@@ -0,0 +1,68 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestSealRecord(t *testing.T) {
+	t.Log("Starting TestSealRecord: Verifying the owner can seal a record")
+	ctx, stub, _ := newMockContext("Org2MSP")
+
+	stub.On("GetState", "REC001").Return(mustMarshal(t, VerificationRecord{ID: "REC001", Party: "Org2MSP", Status: "VERIFIED"}), nil)
+	stub.On("GetTxTimestamp").Return(timestamppb.Now(), nil)
+	var written VerificationRecord
+	stub.On("PutState", "REC001", mock.Anything).Run(func(args mock.Arguments) {
+		_ = json.Unmarshal(args.Get(1).([]byte), &written)
+	}).Return(nil)
+
+	contract := new(HistoryContract)
+	err := contract.SealRecord(ctx, "REC001")
+
+	assert.NoError(t, err)
+	assert.True(t, written.Sealed)
+}
+
+func TestSealedRecordRefusesMutation(t *testing.T) {
+	t.Log("Starting TestSealedRecordRefusesMutation: Verifying every mutating path is blocked after sealing")
+	sealed := VerificationRecord{ID: "REC001", Party: "Org2MSP", Status: "VERIFIED", Version: 2, Sealed: true}
+	contract := new(HistoryContract)
+
+	mutations := map[string]func(ctx *MockTransactionContext) error{
+		"UpdateRecord": func(ctx *MockTransactionContext) error {
+			return contract.UpdateRecord(ctx, "REC001", "Changed", "VERIFIED", "", 2)
+		},
+		"PatchRecord": func(ctx *MockTransactionContext) error {
+			return contract.PatchRecord(ctx, "REC001", `{"description":"Changed"}`)
+		},
+		"DeleteRecord": func(ctx *MockTransactionContext) error {
+			return contract.DeleteRecord(ctx, "REC001")
+		},
+		"DeleteRecordWithTombstone": func(ctx *MockTransactionContext) error {
+			return contract.DeleteRecordWithTombstone(ctx, "REC001", "duplicate entry")
+		},
+		"SetRecordConfidential": func(ctx *MockTransactionContext) error {
+			return contract.SetRecordConfidential(ctx, "REC001", true)
+		},
+		"TransferOwnership": func(ctx *MockTransactionContext) error {
+			return contract.TransferOwnership(ctx, "REC001", "Org3MSP")
+		},
+	}
+
+	for name, mutate := range mutations {
+		ctx, stub, _ := newMockContext("Org2MSP")
+		stub.On("GetState", "REC001").Return(mustMarshal(t, sealed), nil)
+		stub.On("GetTxTimestamp").Return(timestamppb.Now(), nil)
+
+		err := mutate(ctx)
+
+		assert.EqualError(t, err, "record REC001 is sealed", name)
+		stub.AssertNotCalled(t, "PutState", mock.Anything, mock.Anything)
+		stub.AssertNotCalled(t, "DelState", mock.Anything)
+	}
+	t.Log("Update, patch, delete and the other write paths all refused the sealed record")
+}
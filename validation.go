@@ -0,0 +1,116 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// maxRecordIDLength bounds record IDs so keys stay readable and cheap to index
+const maxRecordIDLength = 64
+
+// recordIDPattern is the accepted record ID format: letters, digits, '-' and '_'
+var recordIDPattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// validateRecordID rejects IDs containing characters that could be used to spoof another key,
+// such as Cyrillic lookalikes or zero-width spaces. Only printable ASCII is accepted.
+func validateRecordID(id string) error {
+	for _, r := range id {
+		if r > unicode.MaxASCII || unicode.IsControl(r) {
+			return fmt.Errorf("record ID %q contains disallowed character %U", id, r)
+		}
+	}
+	return nil
+}
+
+// validateRecord checks the client supplied fields of a record before it is written: a well-formed ID,
+// a non-empty description and a status known to the workflow graph. Each field fails with its own error.
+func validateRecord(record *VerificationRecord, graph map[string][]string) error {
+	if record.ID == "" {
+		return fmt.Errorf("record ID must not be empty")
+	}
+	if err := validateRecordID(record.ID); err != nil {
+		return err
+	}
+	if len(record.ID) > maxRecordIDLength {
+		return fmt.Errorf("record ID %s is longer than %d characters", record.ID, maxRecordIDLength)
+	}
+	if !recordIDPattern.MatchString(record.ID) {
+		return fmt.Errorf("record ID %q may only contain letters, digits, '-' and '_'", record.ID)
+	}
+
+	if strings.TrimSpace(record.Description) == "" {
+		return fmt.Errorf("description of record %s must not be empty", record.ID)
+	}
+
+	if !isValidStatus(graph, record.Status) {
+		return fmt.Errorf("unknown status %q for record %s: %w", record.Status, record.ID, ErrInvalidStatus)
+	}
+
+	if err := validateQuantity(record.ID, record.Quantity); err != nil {
+		return err
+	}
+
+	return validateCategory(record.ID, record.Category)
+}
+
+// validateQuantity rejects negative quantities
+func validateQuantity(id string, quantity int64) error {
+	if quantity < 0 {
+		return fmt.Errorf("quantity of record %s must not be negative, got %d", id, quantity)
+	}
+	return nil
+}
+
+// Record categories
+const (
+	CategoryContract    = "CONTRACT"
+	CategoryShipment    = "SHIPMENT"
+	CategoryInvoice     = "INVOICE"
+	CategoryCertificate = "CERTIFICATE"
+)
+
+// AllowedCategories is the set of values accepted in a record's Category field
+var AllowedCategories = map[string]bool{
+	CategoryContract:    true,
+	CategoryShipment:    true,
+	CategoryInvoice:     true,
+	CategoryCertificate: true,
+}
+
+// validateCategory rejects categories outside AllowedCategories; an empty category is accepted
+func validateCategory(id string, category string) error {
+	if category != "" && !AllowedCategories[category] {
+		return fmt.Errorf("unknown category %q for record %s", category, id)
+	}
+	return nil
+}
+
+// parseMetadata decodes a client supplied metadata argument, which must be a JSON object of string values.
+// An empty argument means no metadata and yields nil.
+func parseMetadata(metadataJSON string) (map[string]string, error) {
+	if metadataJSON == "" {
+		return nil, nil
+	}
+
+	var metadata map[string]string
+	if err := json.Unmarshal([]byte(metadataJSON), &metadata); err != nil {
+		return nil, fmt.Errorf("metadata must be a JSON object of strings: %v", err)
+	}
+	if err := validateMetadata(metadata); err != nil {
+		return nil, err
+	}
+	return metadata, nil
+}
+
+// validateMetadata rejects metadata with empty keys
+func validateMetadata(metadata map[string]string) error {
+	for key := range metadata {
+		if key == "" {
+			return fmt.Errorf("metadata keys must not be empty")
+		}
+	}
+	return nil
+}
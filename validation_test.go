@@ -0,0 +1,112 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestCreateRecordRejectsZeroWidthSpaceInID(t *testing.T) {
+	t.Log("Starting TestCreateRecordRejectsZeroWidthSpaceInID: Verifying spoofable IDs are refused")
+	ctx, stub, _ := newMockContext("Org1MSP")
+	stub.On("GetState", compositeKey(configObjectType, configWorkflowGraph)).Return(nil, nil)
+
+	contract := new(HistoryContract)
+	err := contract.CreateRecord(ctx, "REC\u200b001", "Spoofed Draft", "CREATED", "", 0, "")
+
+	assert.EqualError(t, err, `record ID "REC\u200b001" contains disallowed character U+200B`)
+	stub.AssertNotCalled(t, "PutState", mock.Anything, mock.Anything)
+	t.Log("The zero-width space was named in the error")
+}
+
+func TestBatchImportRejectsHomoglyphID(t *testing.T) {
+	ctx, stub, clientIdentity := newMockContext("Org1MSP")
+	clientIdentity.On("GetAttributeValue", CreatorAttribute).Return(CreatorAttributeValue, true, nil)
+	stub.On("GetState", compositeKey(configObjectType, configWorkflowGraph)).Return(nil, nil)
+	stub.On("GetState", compositeKey(configObjectType, configAuthorizedMSPs)).Return(nil, nil)
+
+	contract := new(HistoryContract)
+	// \u0415 is the Cyrillic capital Ie, a lookalike of the Latin E
+	_, err := contract.BatchImport(ctx, `[{"id":"R\u0415C001","status":"CREATED"}]`, "", BatchModeAbort)
+
+	assert.EqualError(t, err, "record ID \"R\u0415C001\" contains disallowed character U+0415")
+	stub.AssertNotCalled(t, "PutState", mock.Anything, mock.Anything)
+}
+
+func TestValidateRecordIDAcceptsASCII(t *testing.T) {
+	assert.NoError(t, validateRecordID("REC-001_a"))
+}
+
+func TestValidateRecordRejections(t *testing.T) {
+	t.Log("Starting TestValidateRecordRejections: Verifying each invalid field fails with its own error")
+	cases := map[string]struct {
+		record   VerificationRecord
+		expected string
+	}{
+		"empty ID":            {VerificationRecord{Description: "Manifest", Status: "CREATED"}, "record ID must not be empty"},
+		"ID too long":         {VerificationRecord{ID: strings.Repeat("R", 65), Description: "Manifest", Status: "CREATED"}, "record ID " + strings.Repeat("R", 65) + " is longer than 64 characters"},
+		"ID with separator":   {VerificationRecord{ID: "REC/001", Description: "Manifest", Status: "CREATED"}, `record ID "REC/001" may only contain letters, digits, '-' and '_'`},
+		"blank description":   {VerificationRecord{ID: "REC001", Description: "   ", Status: "CREATED"}, "description of record REC001 must not be empty"},
+		"misspelled status":   {VerificationRecord{ID: "REC001", Description: "Manifest", Status: "VERIFED"}, `unknown status "VERIFED" for record REC001: invalid status`},
+		"lowercase status":    {VerificationRecord{ID: "REC001", Description: "Manifest", Status: "created"}, `unknown status "created" for record REC001: invalid status`},
+		"control character":   {VerificationRecord{ID: "REC\n001", Description: "Manifest", Status: "CREATED"}, `record ID "REC\n001" contains disallowed character U+000A`},
+		"empty status string": {VerificationRecord{ID: "REC001", Description: "Manifest"}, `unknown status "" for record REC001: invalid status`},
+	}
+
+	for name, tc := range cases {
+		err := validateRecord(&tc.record, AllowedTransitions)
+		assert.EqualError(t, err, tc.expected, name)
+	}
+
+	valid := VerificationRecord{ID: "REC-001_a", Description: "Manifest", Status: StatusArchived}
+	assert.NoError(t, validateRecord(&valid, AllowedTransitions))
+}
+
+func TestUpdateRecordRejectsBlankDescription(t *testing.T) {
+	ctx, stub, _ := newMockContext("Org2MSP")
+	stub.On("GetState", "REC001").Return(mustMarshal(t, VerificationRecord{ID: "REC001", Description: "Initial", Party: "Org2MSP", Status: "CREATED", Version: 1}), nil)
+	stub.On("GetState", compositeKey(configObjectType, configWorkflowGraph)).Return(nil, nil)
+
+	contract := new(HistoryContract)
+	err := contract.UpdateRecord(ctx, "REC001", "", "PENDING", "", 1)
+
+	assert.EqualError(t, err, "description of record REC001 must not be empty")
+	stub.AssertNotCalled(t, "PutState", mock.Anything, mock.Anything)
+}
+
+func TestNegativeQuantityRejected(t *testing.T) {
+	t.Log("Starting TestNegativeQuantityRejected: Verifying negative quantities are refused on create and patch")
+	contract := new(HistoryContract)
+
+	ctx, stub, clientIdentity := newMockContext("Org2MSP")
+	stub.On("GetState", compositeKey(configObjectType, configWorkflowGraph)).Return(nil, nil)
+	clientIdentity.On("GetAttributeValue", CreatorAttribute).Return(CreatorAttributeValue, true, nil)
+	err := contract.CreateRecord(ctx, "REC001", "Tea crates", "CREATED", "", -5, "")
+	assert.EqualError(t, err, "quantity of record REC001 must not be negative, got -5")
+
+	stub.On("GetState", "REC002").Return(mustMarshal(t, VerificationRecord{ID: "REC002", Description: "Tea crates", Party: "Org2MSP", Status: "CREATED", Quantity: 10}), nil)
+	err = contract.PatchRecord(ctx, "REC002", `{"quantity":-1}`)
+	assert.EqualError(t, err, "quantity of record REC002 must not be negative, got -1")
+
+	stub.AssertNotCalled(t, "PutState", mock.Anything, mock.Anything)
+}
+
+func TestCategoryValidation(t *testing.T) {
+	t.Log("Starting TestCategoryValidation: Verifying only allowed categories are accepted")
+	contract := new(HistoryContract)
+
+	ctx, stub, clientIdentity := newMockContext("Org2MSP")
+	stub.On("GetState", compositeKey(configObjectType, configWorkflowGraph)).Return(nil, nil)
+	clientIdentity.On("GetAttributeValue", CreatorAttribute).Return(CreatorAttributeValue, true, nil)
+	err := contract.CreateRecord(ctx, "REC001", "Tea crates", "CREATED", "", 0, "FURNITURE")
+	assert.EqualError(t, err, `unknown category "FURNITURE" for record REC001`)
+	stub.AssertNotCalled(t, "PutState", mock.Anything, mock.Anything)
+
+	for _, category := range []string{"", CategoryContract, CategoryShipment} {
+		record := VerificationRecord{ID: "REC001", Description: "Tea crates", Status: "CREATED", Category: category}
+		assert.NoError(t, validateRecord(&record, AllowedTransitions), "category %q", category)
+	}
+	t.Log("Unknown categories were refused and empty or allowed ones accepted")
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// minDescriptionLength is the description length below which a record is flagged as too terse
+const minDescriptionLength = 10
+
+// RecordWithWarnings pairs a committed record with non-fatal data-quality hints
+type RecordWithWarnings struct {
+	Record   *VerificationRecord `json:"record"`
+	Warnings []string            `json:"warnings"`
+}
+
+// CreateRecordWithWarnings creates a record exactly like CreateRecord, and also returns
+// warnings about data quality. Warnings never block the write.
+func (s *HistoryContract) CreateRecordWithWarnings(ctx contractapi.TransactionContextInterface, id string, description string, status string) (*RecordWithWarnings, error) {
+	record, err := s.createRecord(ctx, id, description, status, nil, 0, "")
+	if err != nil {
+		return nil, err
+	}
+
+	return &RecordWithWarnings{Record: record, Warnings: recordWarnings(record)}, nil
+}
+
+// recordWarnings lists the data-quality hints for a record; the result is empty, not nil, when there are none
+func recordWarnings(record *VerificationRecord) []string {
+	warnings := []string{}
+	if len(strings.TrimSpace(record.Description)) < minDescriptionLength {
+		warnings = append(warnings, fmt.Sprintf("description is very short (fewer than %d characters)", minDescriptionLength))
+	}
+	return warnings
+}
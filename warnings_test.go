@@ -0,0 +1,32 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestCreateRecordWithWarningsShortDescription(t *testing.T) {
+	t.Log("Starting TestCreateRecordWithWarningsShortDescription: Verifying a short description warns but still commits")
+	ctx, stub, clientIdentity := newMockContext("Org1MSP")
+	stub.On("GetState", compositeKey(configObjectType, configWorkflowGraph)).Return(nil, nil)
+	stub.On("GetState", compositeKey(configObjectType, configAuthorizedMSPs)).Return(nil, nil)
+	clientIdentity.On("GetAttributeValue", CreatorAttribute).Return(CreatorAttributeValue, true, nil)
+
+	stub.On("GetState", "REC001").Return(nil, nil)
+	stub.On("GetTxTimestamp").Return(timestamppb.Now(), nil)
+	stub.On("PutState", "REC001", mock.Anything).Return(nil)
+	stub.On("PutState", mock.Anything, indexEntryValue).Return(nil)
+	stub.On("SetEvent", "RecordCreated", mock.Anything).Return(nil)
+
+	contract := new(HistoryContract)
+	result, err := contract.CreateRecordWithWarnings(ctx, "REC001", "Draft", "CREATED")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "REC001", result.Record.ID)
+	assert.Equal(t, []string{"description is very short (fewer than 10 characters)"}, result.Warnings)
+	stub.AssertCalled(t, "PutState", "REC001", mock.Anything)
+	t.Log("Record was committed and the short description was reported as a warning")
+}
@@ -0,0 +1,222 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// Record statuses understood by the built-in workflow
+const (
+	StatusCreated  = "CREATED"
+	StatusPending  = "PENDING"
+	StatusVerified = "VERIFIED"
+	StatusRejected = "REJECTED"
+	StatusArchived = "ARCHIVED"
+)
+
+// AllowedTransitions is the built-in status state machine, used until an admin stores a custom graph.
+// Each status maps to the statuses it may move to; an empty list marks a terminal status.
+var AllowedTransitions = map[string][]string{
+	StatusCreated:  {StatusPending, StatusRejected},
+	StatusPending:  {StatusVerified, StatusRejected},
+	StatusVerified: {StatusArchived},
+	StatusRejected: {StatusArchived},
+	StatusArchived: {},
+}
+
+// SetWorkflowGraph stores a custom allowed-transition graph (admin only), e.g.
+// {"CREATED":["PENDING"],"PENDING":["VERIFIED"],"VERIFIED":[]}.
+// Every status used as a target must also be declared as a key so the graph is complete.
+func (s *HistoryContract) SetWorkflowGraph(ctx contractapi.TransactionContextInterface, graphJSON string) error {
+	if err := requireAdmin(ctx); err != nil {
+		return err
+	}
+
+	var graph map[string][]string
+	if err := json.Unmarshal([]byte(graphJSON), &graph); err != nil {
+		return fmt.Errorf("failed to unmarshal workflow graph: %v", err)
+	}
+	if err := validateWorkflowGraph(graph); err != nil {
+		return err
+	}
+
+	// Re-marshal so the stored form is canonical regardless of client formatting
+	canonical, err := json.Marshal(graph)
+	if err != nil {
+		return err
+	}
+	return putConfigValue(ctx, configWorkflowGraph, string(canonical))
+}
+
+// validateWorkflowGraph checks that a graph is non-empty and only references declared statuses
+func validateWorkflowGraph(graph map[string][]string) error {
+	if len(graph) == 0 {
+		return fmt.Errorf("workflow graph must declare at least one status")
+	}
+	for from, targets := range graph {
+		if from == "" {
+			return fmt.Errorf("workflow graph contains an empty status")
+		}
+		for _, to := range targets {
+			if _, declared := graph[to]; !declared {
+				return fmt.Errorf("workflow graph transition %s -> %s targets an undeclared status", from, to)
+			}
+		}
+	}
+	return nil
+}
+
+// getWorkflowGraph returns the stored workflow graph, falling back to the built-in default
+func getWorkflowGraph(ctx contractapi.TransactionContextInterface) (map[string][]string, error) {
+	value, found, err := getConfigValue(ctx, configWorkflowGraph)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return AllowedTransitions, nil
+	}
+
+	var graph map[string][]string
+	if err := json.Unmarshal([]byte(value), &graph); err != nil {
+		return nil, fmt.Errorf("invalid %s configuration: %v", configWorkflowGraph, err)
+	}
+	return graph, nil
+}
+
+// isValidTransition reports whether a record may move from one status to another under the active workflow.
+// Keeping the same status (e.g. a description-only update) is always allowed.
+func isValidTransition(ctx contractapi.TransactionContextInterface, from string, to string) (bool, error) {
+	if from == to {
+		return true, nil
+	}
+
+	graph, err := getWorkflowGraph(ctx)
+	if err != nil {
+		return false, err
+	}
+	return slices.Contains(graph[from], to), nil
+}
+
+// isValidStatus reports whether status belongs to the workflow graph, either as a source or as a target.
+// The graph rather than the Status constants is the reference, so custom workflows can add statuses.
+func isValidStatus(graph map[string][]string, status string) bool {
+	if _, ok := graph[status]; ok {
+		return true
+	}
+	for _, targets := range graph {
+		if slices.Contains(targets, status) {
+			return true
+		}
+	}
+	return false
+}
+
+// requireKnownStatus rejects a status that the active workflow does not know, catching typos such as "VERIFED"
+func requireKnownStatus(ctx contractapi.TransactionContextInterface, status string) error {
+	graph, err := getWorkflowGraph(ctx)
+	if err != nil {
+		return err
+	}
+	if !isValidStatus(graph, status) {
+		return fmt.Errorf("unknown status %q: %w", status, ErrInvalidStatus)
+	}
+	return nil
+}
+
+// GetAllowedStatuses lists every status of the active workflow in alphabetical order, e.g. to populate dropdowns
+func (s *HistoryContract) GetAllowedStatuses(ctx contractapi.TransactionContextInterface) ([]string, error) {
+	graph, err := getWorkflowGraph(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := []string{}
+	for from, targets := range graph {
+		for _, status := range append([]string{from}, targets...) {
+			if !slices.Contains(statuses, status) {
+				statuses = append(statuses, status)
+			}
+		}
+	}
+	sort.Strings(statuses)
+	return statuses, nil
+}
+
+// GetAllowedTransitions lists the statuses a record may move to next under the active workflow, so clients
+// only offer actions that UpdateRecord would accept. Terminal statuses yield an empty list.
+func (s *HistoryContract) GetAllowedTransitions(ctx contractapi.TransactionContextInterface, id string) ([]string, error) {
+	record, err := s.readRecord(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	graph, err := getWorkflowGraph(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	next := []string{}
+	next = append(next, graph[record.Status]...)
+	return next, nil
+}
+
+// CustodyReport is the outcome of replaying a record's history against the workflow
+type CustodyReport struct {
+	Valid      bool     `json:"valid"`
+	Violations []string `json:"violations"`
+}
+
+// VerifyCustodyChain replays a record's history from oldest to newest and checks every status change
+// against the active workflow. Each illegal change is reported as "<txId>: <from> -> <to>", naming the
+// skipped status when a single intermediate step would have made it legal.
+// A delete marker ends the chain; a record recreated afterwards starts a new one.
+func (s *HistoryContract) VerifyCustodyChain(ctx contractapi.TransactionContextInterface, id string) (*CustodyReport, error) {
+	history, err := s.getChronologicalHistory(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(history) == 0 {
+		return nil, fmt.Errorf("record %s has no history", id)
+	}
+
+	graph, err := getWorkflowGraph(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &CustodyReport{Violations: []string{}}
+	previous := ""
+	for _, entry := range history {
+		if entry.IsDelete || entry.Record == nil {
+			previous = ""
+			continue
+		}
+
+		current := entry.Record.Status
+		if previous != "" && previous != current && !slices.Contains(graph[previous], current) {
+			violation := fmt.Sprintf("%s: %s -> %s", entry.TxId, previous, current)
+			if skipped := skippedStatus(graph, previous, current); skipped != "" {
+				violation += " skipped " + skipped
+			}
+			report.Violations = append(report.Violations, violation)
+		}
+		previous = current
+	}
+
+	report.Valid = len(report.Violations) == 0
+	return report, nil
+}
+
+// skippedStatus returns the status that would have made from -> to legal in two steps, if there is one
+func skippedStatus(graph map[string][]string, from string, to string) string {
+	for _, intermediate := range graph[from] {
+		if slices.Contains(graph[intermediate], to) {
+			return intermediate
+		}
+	}
+	return ""
+}
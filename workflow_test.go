@@ -0,0 +1,209 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestCustomWorkflowGraph(t *testing.T) {
+	t.Log("Starting TestCustomWorkflowGraph: Verifying transitions are validated against a stored custom graph")
+	ctx, stub, _ := newMockContext(AdminMSPID)
+
+	graphKey := compositeKey(configObjectType, configWorkflowGraph)
+	customGraph := `{"CREATED":["SHIPPED"],"SHIPPED":["VERIFIED"],"VERIFIED":[]}`
+	stub.On("PutState", graphKey, []byte(customGraph)).Return(nil)
+
+	t.Log("Storing a custom workflow graph as admin...")
+	contract := new(HistoryContract)
+	err := contract.SetWorkflowGraph(ctx, customGraph)
+	assert.NoError(t, err)
+	stub.AssertExpectations(t)
+
+	stub.On("GetState", graphKey).Return([]byte(customGraph), nil)
+
+	t.Log("Validating transitions against the custom graph...")
+	valid, err := isValidTransition(ctx, "CREATED", "SHIPPED")
+	assert.NoError(t, err)
+	assert.True(t, valid)
+
+	// Allowed by the built-in default but not by the custom workflow
+	valid, err = isValidTransition(ctx, "CREATED", "PENDING")
+	assert.NoError(t, err)
+	assert.False(t, valid)
+	t.Log("The custom graph replaced the built-in workflow")
+}
+
+func TestWorkflowGraphFallsBackToDefault(t *testing.T) {
+	ctx, stub, _ := newMockContext("Org2MSP")
+	stub.On("GetState", compositeKey(configObjectType, configWorkflowGraph)).Return(nil, nil)
+
+	valid, err := isValidTransition(ctx, "CREATED", "PENDING")
+	assert.NoError(t, err)
+	assert.True(t, valid)
+
+	valid, err = isValidTransition(ctx, "CREATED", "VERIFIED")
+	assert.NoError(t, err)
+	assert.False(t, valid)
+}
+
+func TestSetWorkflowGraphRejectsUndeclaredTarget(t *testing.T) {
+	ctx, _, _ := newMockContext(AdminMSPID)
+
+	contract := new(HistoryContract)
+	err := contract.SetWorkflowGraph(ctx, `{"CREATED":["SHIPPED"]}`)
+	assert.EqualError(t, err, "workflow graph transition CREATED -> SHIPPED targets an undeclared status")
+}
+
+func TestUpdateRecordLegalTransition(t *testing.T) {
+	t.Log("Starting TestUpdateRecordLegalTransition: Verifying a CREATED record can move to PENDING")
+	ctx, stub, _ := newMockContext("Org1MSP")
+
+	existing := &VerificationRecord{ID: "REC001", Description: "Initial", Party: "Org1MSP", Status: StatusCreated, Version: 1}
+	stub.On("GetState", "REC001").Return(mustMarshal(t, existing), nil)
+	stub.On("GetState", compositeKey(configObjectType, configWorkflowGraph)).Return(nil, nil)
+	stub.On("GetTxTimestamp").Return(timestamppb.Now(), nil)
+	stub.On("PutState", "REC001", mock.Anything).Return(nil)
+	stub.On("PutState", mock.Anything, indexEntryValue).Return(nil)
+	stub.On("DelState", mock.Anything).Return(nil)
+	stub.On("SetEvent", "RecordUpdated", mock.Anything).Return(nil)
+
+	contract := new(HistoryContract)
+	err := contract.UpdateRecord(ctx, "REC001", "Submitted for review", StatusPending, "", 1)
+
+	assert.NoError(t, err)
+	stub.AssertCalled(t, "PutState", "REC001", mock.Anything)
+	t.Log("CREATED -> PENDING was accepted")
+}
+
+func TestUpdateRecordIllegalTransition(t *testing.T) {
+	t.Log("Starting TestUpdateRecordIllegalTransition: Verifying a CREATED record cannot jump to VERIFIED")
+	ctx, stub, _ := newMockContext("Org1MSP")
+
+	existing := &VerificationRecord{ID: "REC001", Description: "Initial", Party: "Org1MSP", Status: StatusCreated, Version: 1}
+	stub.On("GetState", "REC001").Return(mustMarshal(t, existing), nil)
+	stub.On("GetState", compositeKey(configObjectType, configWorkflowGraph)).Return(nil, nil)
+
+	contract := new(HistoryContract)
+	err := contract.UpdateRecord(ctx, "REC001", "Skipping review", StatusVerified, "", 1)
+
+	assert.EqualError(t, err, "invalid status transition from CREATED to VERIFIED: invalid status")
+	stub.AssertNotCalled(t, "PutState", mock.Anything, mock.Anything)
+	t.Log("CREATED -> VERIFIED was rejected without writing")
+}
+
+func TestUpdateRecordIfMatchingStatus(t *testing.T) {
+	t.Log("Starting TestUpdateRecordIfMatchingStatus: Verifying the update applies while the precondition holds")
+	ctx, stub, _ := newMockContext("Org1MSP")
+
+	existing := &VerificationRecord{ID: "REC001", Description: "Manifest", Party: "Org1MSP", Status: StatusPending}
+	stub.On("GetState", "REC001").Return(mustMarshal(t, existing), nil)
+	stub.On("GetState", compositeKey(configObjectType, configWorkflowGraph)).Return(nil, nil)
+	stub.On("GetTxTimestamp").Return(timestamppb.Now(), nil)
+	stub.On("PutState", "REC001", mock.Anything).Return(nil)
+	stub.On("PutState", mock.Anything, indexEntryValue).Return(nil)
+	stub.On("DelState", mock.Anything).Return(nil)
+	stub.On("SetEvent", "RecordUpdated", mock.Anything).Return(nil)
+
+	contract := new(HistoryContract)
+	err := contract.UpdateRecordIf(ctx, "REC001", StatusPending, "Inspected", StatusVerified)
+
+	assert.NoError(t, err)
+	stub.AssertCalled(t, "PutState", "REC001", mock.Anything)
+}
+
+func TestUpdateRecordIfMismatchedStatus(t *testing.T) {
+	t.Log("Starting TestUpdateRecordIfMismatchedStatus: Verifying a stale precondition blocks the update")
+	ctx, stub, _ := newMockContext("Org1MSP")
+	stub.On("GetState", compositeKey(configObjectType, configWorkflowGraph)).Return(nil, nil)
+
+	existing := &VerificationRecord{ID: "REC001", Description: "Manifest", Party: "Org1MSP", Status: StatusRejected}
+	stub.On("GetState", "REC001").Return(mustMarshal(t, existing), nil)
+
+	contract := new(HistoryContract)
+	err := contract.UpdateRecordIf(ctx, "REC001", StatusPending, "Inspected", StatusVerified)
+
+	assert.EqualError(t, err, "precondition failed: expected status PENDING but found REJECTED")
+	stub.AssertNotCalled(t, "PutState", mock.Anything, mock.Anything)
+}
+
+func TestVerifyCustodyChainIllegalJump(t *testing.T) {
+	t.Log("Starting TestVerifyCustodyChainIllegalJump: Verifying an illegal jump is reported with the skipped status")
+	ctx, stub, _ := newMockContext("Org1MSP")
+
+	base := time.Date(2024, 3, 1, 8, 0, 0, 0, time.UTC)
+	stub.On("GetHistoryForKey", "REC001").Return(newHistoryIterator(
+		historyEntry(t, "tx4", base.Add(3*time.Hour), &VerificationRecord{ID: "REC001", Status: "ARCHIVED"}),
+		historyEntry(t, "tx3", base.Add(2*time.Hour), &VerificationRecord{ID: "REC001", Status: "VERIFIED"}),
+		historyEntry(t, "tx2", base.Add(time.Hour), &VerificationRecord{ID: "REC001", Status: "CREATED", Description: "Corrected"}),
+		historyEntry(t, "tx1", base, &VerificationRecord{ID: "REC001", Status: "CREATED"}),
+	), nil)
+	stub.On("GetState", compositeKey(configObjectType, configWorkflowGraph)).Return(nil, nil)
+
+	contract := new(HistoryContract)
+	report, err := contract.VerifyCustodyChain(ctx, "REC001")
+
+	assert.NoError(t, err)
+	assert.False(t, report.Valid)
+	assert.Equal(t, []string{"tx3: CREATED -> VERIFIED skipped PENDING"}, report.Violations)
+	t.Log("Only the CREATED -> VERIFIED jump was flagged")
+}
+
+func TestGetAllowedStatuses(t *testing.T) {
+	t.Log("Starting TestGetAllowedStatuses: Verifying every workflow status is listed once")
+	ctx, stub, _ := newMockContext("Org2MSP")
+	stub.On("GetState", compositeKey(configObjectType, configWorkflowGraph)).Return(nil, nil)
+
+	contract := new(HistoryContract)
+	statuses, err := contract.GetAllowedStatuses(ctx)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{StatusArchived, StatusCreated, StatusPending, StatusRejected, StatusVerified}, statuses)
+}
+
+func TestIsValidStatus(t *testing.T) {
+	for _, status := range []string{StatusCreated, StatusPending, StatusVerified, StatusRejected, StatusArchived} {
+		assert.True(t, isValidStatus(AllowedTransitions, status), status)
+	}
+	for _, status := range []string{"VERIFED", "verified", "", "SHIPPED"} {
+		assert.False(t, isValidStatus(AllowedTransitions, status), status)
+	}
+}
+
+func TestUnknownStatusRejectedAtBoundary(t *testing.T) {
+	t.Log("Starting TestUnknownStatusRejectedAtBoundary: Verifying a misspelled status is refused before any work")
+	ctx, stub, _ := newMockContext("Org2MSP")
+	stub.On("GetState", compositeKey(configObjectType, configWorkflowGraph)).Return(nil, nil)
+
+	contract := new(HistoryContract)
+	_, err := contract.QueryRecordsByStatus(ctx, "VERIFED")
+	assert.EqualError(t, err, `unknown status "VERIFED": invalid status`)
+
+	_, err = contract.BulkUpdateStatus(ctx, []string{"REC001"}, "VERIFED")
+	assert.EqualError(t, err, `unknown status "VERIFED": invalid status`)
+
+	stub.AssertNotCalled(t, "GetQueryResult", mock.Anything)
+	stub.AssertNotCalled(t, "GetState", "REC001")
+}
+
+func TestGetAllowedTransitions(t *testing.T) {
+	t.Log("Starting TestGetAllowedTransitions: Verifying the next statuses come from the workflow")
+	ctx, stub, _ := newMockContext("Org2MSP")
+	stub.On("GetState", compositeKey(configObjectType, configWorkflowGraph)).Return(nil, nil)
+	stub.On("GetState", "REC001").Return(mustMarshal(t, VerificationRecord{ID: "REC001", Status: StatusPending}), nil)
+	stub.On("GetState", "REC002").Return(mustMarshal(t, VerificationRecord{ID: "REC002", Status: StatusArchived}), nil)
+
+	contract := new(HistoryContract)
+	next, err := contract.GetAllowedTransitions(ctx, "REC001")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{StatusVerified, StatusRejected}, next)
+
+	next, err = contract.GetAllowedTransitions(ctx, "REC002")
+	assert.NoError(t, err)
+	assert.NotNil(t, next)
+	assert.Empty(t, next)
+	t.Log("PENDING offered VERIFIED and REJECTED while ARCHIVED offered nothing")
+}